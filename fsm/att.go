@@ -0,0 +1,296 @@
+package fsm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ATTEpsilon is the symbol of label 0 in OpenFst symbol tables, the empty transition
+const ATTEpsilon = "<eps>"
+
+// SymbolTable maps the symbols of an OpenFst symbol table to their integer labels
+type SymbolTable map[string]int
+
+// ReadSymbolTable reads an OpenFst symbol table, one "symbol label" line per symbol
+func ReadSymbolTable(r io.Reader) (SymbolTable, error) {
+	table := make(SymbolTable)
+	labels := make(map[int]string)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected symbol and label, got %q", n, scanner.Text())
+		}
+		label, err := strconv.Atoi(fields[1])
+		if err != nil || label < 0 {
+			return nil, fmt.Errorf("line %d: label %s of symbol %s is not a non-negative integer", n, fields[1], fields[0])
+		}
+		if _, exists := table[fields[0]]; exists {
+			return nil, fmt.Errorf("line %d: symbol %s is defined twice", n, fields[0])
+		}
+		if other, exists := labels[label]; exists {
+			return nil, fmt.Errorf("line %d: label %d is used by both %s and %s", n, label, other, fields[0])
+		}
+		table[fields[0]], labels[label] = label, fields[0]
+	}
+	return table, scanner.Err()
+}
+
+// Write writes the symbol table, sorted by label
+func (s SymbolTable) Write(w io.Writer) error {
+	symbols := make([]string, 0, len(s))
+	for symbol := range s {
+		symbols = append(symbols, symbol)
+	}
+	sort.Slice(symbols, func(i, j int) bool { return s[symbols[i]] < s[symbols[j]] })
+	var b strings.Builder
+	for _, symbol := range symbols {
+		fmt.Fprintf(&b, "%s\t%d\n", symbol, s[symbol])
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// resolve returns the symbol of field. A numeric field is a label, translated into its symbol; otherwise field is a symbol,
+// which must be in the table. Without a table, field is used as is. table names the table in errors
+func (s SymbolTable) resolve(field string, numeric bool, table string) (string, error) {
+	if numeric {
+		label, err := strconv.Atoi(field)
+		if err != nil || label < 0 {
+			return "", fmt.Errorf("%s is not a non-negative integer", field)
+		}
+		if s == nil {
+			return field, nil
+		}
+		for symbol, l := range s {
+			if l == label {
+				return symbol, nil
+			}
+		}
+	} else if _, exists := s[field]; s == nil || exists {
+		return field, nil
+	}
+	return "", fmt.Errorf("%s is not in the %s symbol table", field, table)
+}
+
+// ATTOptions configures reading the AT&T FSM text format
+type ATTOptions struct {
+	NumericLabels bool        // NumericLabels reads states and labels as numbers, like fstprint writes them without symbol tables, instead of symbols
+	InputSymbols  SymbolTable // InputSymbols, if not nil, translates numeric arc labels into symbols, and rejects unknown labels or symbols
+	OutputSymbols SymbolTable // OutputSymbols, if not nil, does the same for output labels of transducers. InputSymbols is used if nil
+	StateSymbols  SymbolTable // StateSymbols, if not nil, translates numeric states into names, and rejects unknown states
+	Acceptor      bool        // Acceptor reads 4 field arcs as "src dst label weight" instead of "src dst ilabel olabel"
+}
+
+// ATTWarning reports something of an AT&T file that a FiniteAutomaton can't represent, and was left out
+type ATTWarning struct {
+	Line    int
+	Message string
+}
+
+func (w ATTWarning) String() string {
+	return fmt.Sprintf("line %d: %s", w.Line, w.Message)
+}
+
+// attWeight parses a weight of the tropical semiring, where 0 is the identity, the OpenFst default, and Infinity is zero
+func attWeight(n int, weight string) (float64, error) {
+	w, err := strconv.ParseFloat(weight, 64)
+	if err != nil {
+		return 0, fmt.Errorf("line %d: weight %s is not a number", n, weight)
+	}
+	return w, nil
+}
+
+// ReadATT reads an acceptor in the AT&T FSM text format used by OpenFst: "src dst ilabel [olabel] [weight]" arc lines and
+// "state [weight]" final state lines. The initial state is the first state of the first line.
+//
+//	Weights other than 0 and output labels different from the input label can't be represented by a FiniteAutomaton. They are
+//	left out and returned as warnings. A final weight of Infinity means the state is not final. Weights that are not numbers,
+//	ε transitions (label 0 or <eps>), several arcs on the same label and missing arcs are errors. Fields are symbols, or numbers
+//	with NumericLabels: a field is never guessed to be one or the other
+func ReadATT(r io.Reader, options ATTOptions) (*FiniteAutomaton, []ATTWarning, error) {
+	var warnings []ATTWarning
+	Q, Sigma, F := NewSet[string](), NewSet[string](), NewSet[string]()
+	delta := make(map[string]map[string]string)
+	q0 := ""
+	state := func(n int, field string) (string, error) {
+		name, err := options.StateSymbols.resolve(field, options.NumericLabels, "state")
+		if err != nil {
+			return "", fmt.Errorf("line %d: state %w", n, err)
+		}
+		if q0 == "" {
+			q0 = name
+		}
+		Q.Add(name)
+		return name, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 0:
+			continue
+		case len(fields) <= 2: // final state
+			name, err := state(n, fields[0])
+			if err != nil {
+				return nil, nil, err
+			}
+			weight := 0.0
+			if len(fields) == 2 {
+				if weight, err = attWeight(n, fields[1]); err != nil {
+					return nil, nil, err
+				}
+			}
+			switch {
+			case math.IsInf(weight, 1):
+				continue // the zero weight, the state is not final
+			case weight != 0:
+				warnings = append(warnings, ATTWarning{n, fmt.Sprintf("final weight %s of state %s is dropped", fields[1], name)})
+			}
+			F.Add(name)
+		case len(fields) <= 5: // arc
+			from, err := state(n, fields[0])
+			if err != nil {
+				return nil, nil, err
+			}
+			to, err := state(n, fields[1])
+			if err != nil {
+				return nil, nil, err
+			}
+			input, err := options.InputSymbols.resolve(fields[2], options.NumericLabels, "input")
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: label %w", n, err)
+			}
+			epsilon := input == ATTEpsilon || options.InputSymbols != nil && options.InputSymbols[input] == 0
+			if label, _ := strconv.Atoi(fields[2]); options.NumericLabels && label == 0 {
+				epsilon = true
+			}
+			if epsilon {
+				return nil, nil, fmt.Errorf("line %d: ε transition from %s to %s, only deterministic acceptors are supported", n, from, to)
+			}
+			output, weight := "", ""
+			switch {
+			case len(fields) == 4 && options.Acceptor:
+				weight = fields[3]
+			case len(fields) >= 4:
+				output = fields[3]
+				if len(fields) == 5 {
+					weight = fields[4]
+				}
+			}
+			if output != "" {
+				outputSymbols := options.OutputSymbols
+				if outputSymbols == nil {
+					outputSymbols = options.InputSymbols
+				}
+				resolved, err := outputSymbols.resolve(output, options.NumericLabels, "output")
+				if err != nil {
+					return nil, nil, fmt.Errorf("line %d: output label %w", n, err)
+				}
+				if resolved != input {
+					warnings = append(warnings, ATTWarning{n, fmt.Sprintf("output label %s of arc %s -%s-> %s is dropped", resolved, from, input, to)})
+				}
+			}
+			if weight != "" {
+				w, err := attWeight(n, weight)
+				if err != nil {
+					return nil, nil, err
+				}
+				if w != 0 {
+					warnings = append(warnings, ATTWarning{n, fmt.Sprintf("weight %s of arc %s -%s-> %s is dropped", weight, from, input, to)})
+				}
+			}
+			if delta[from] == nil {
+				delta[from] = make(map[string]string)
+			}
+			if previous, exists := delta[from][input]; exists && previous != to {
+				return nil, nil, fmt.Errorf("line %d: state %s has arcs on %s to both %s and %s, only deterministic acceptors are supported", n, from, input, previous, to)
+			}
+			delta[from][input] = to
+			Sigma.Add(input)
+		default:
+			return nil, nil, fmt.Errorf("line %d: expected at most 5 fields, got %q", n, scanner.Text())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if q0 == "" {
+		return nil, nil, fmt.Errorf("AT&T file is empty")
+	}
+	for _, from := range sortedStrings(Q) {
+		var missing []string
+		for _, input := range sortedStrings(Sigma) {
+			if _, exists := delta[from][input]; !exists {
+				missing = append(missing, input)
+			}
+		}
+		if len(missing) > 0 {
+			return nil, nil, fmt.Errorf("state %s has no arc on %s, the acceptor must be complete", from, strings.Join(missing, ", "))
+		}
+	}
+	fa, err := NewFiniteAutomaton(Q, Sigma, q0, F, delta)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fa, warnings, nil
+}
+
+// ATTSymbols returns symbol tables for the FA, to compile the output of WriteATT with fstcompile --acceptor.
+// States are numbered from q0 as 0, then in sorted order. Inputs are numbered from 1 in sorted order, 0 is ATTEpsilon
+func (f *FiniteAutomaton) ATTSymbols() (states SymbolTable, inputs SymbolTable, err error) {
+	if f.Sigma.Contains(ATTEpsilon) {
+		return nil, nil, fmt.Errorf("input %s is reserved for label 0", ATTEpsilon)
+	}
+	states = SymbolTable{f.q0: 0}
+	for _, state := range sortedStrings(f.Q) {
+		if state != f.q0 {
+			states[state] = len(states)
+		}
+	}
+	inputs = SymbolTable{ATTEpsilon: 0}
+	for _, input := range sortedStrings(f.Sigma) {
+		inputs[input] = len(inputs)
+	}
+	return states, inputs, nil
+}
+
+// WriteATT writes the FA as an acceptor in the AT&T FSM text format, with state names and inputs as they are. Arcs of q0 come
+// first, so it is the initial state. Use ATTSymbols for the symbol tables, to compile it with OpenFst or to read
+// back its numeric fstprint form with NumericLabels. Names with whitespace can't be written
+func (f *FiniteAutomaton) WriteATT(w io.Writer) error {
+	for _, name := range append(sortedStrings(f.Q), sortedStrings(f.Sigma)...) {
+		if name == "" || strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+			return fmt.Errorf("%q is empty or contains whitespace, it can't be written in the AT&T format", name)
+		}
+	}
+	states := []string{f.q0}
+	for _, state := range sortedStrings(f.Q) {
+		if state != f.q0 {
+			states = append(states, state)
+		}
+	}
+	var b strings.Builder
+	for _, from := range states {
+		for _, input := range sortedStrings(f.Sigma) {
+			fmt.Fprintf(&b, "%s\t%s\t%s\n", from, f.Delta[from][input], input)
+		}
+	}
+	for _, state := range states {
+		if f.F.Contains(state) {
+			fmt.Fprintf(&b, "%s\n", state)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,196 @@
+package fsm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadATT(t *testing.T) {
+
+	endsInOneFA, _ := NewFiniteAutomaton(
+		NewSet("0", "1"),
+		NewSet("a", "b"), "0", NewSet("1"),
+		map[string]map[string]string{
+			"0": {"a": "0", "b": "1"},
+			"1": {"a": "0", "b": "1"},
+		})
+	symbols := SymbolTable{ATTEpsilon: 0, "a": 1, "b": 2}
+
+	tests := []struct {
+		name         string
+		data         string
+		options      ATTOptions
+		wantWarnings []string
+	}{
+		{
+			name: "acceptor with symbols",
+			data: "0\t0\ta\n0\t1\tb\n1\t0\ta\n1\t1\tb\n1\n",
+		},
+		{
+			name:    "numeric labels with a symbol table",
+			data:    "0 0 1\n0 1 2\n1 0 1\n1 1 2\n1 0\n",
+			options: ATTOptions{NumericLabels: true, InputSymbols: symbols},
+		},
+		{
+			name:         "acceptor weights are reported",
+			data:         "0 0 a 0\n0 1 b 0.5\n1 0 a\n1 1 b\n1 2.5\n",
+			options:      ATTOptions{Acceptor: true},
+			wantWarnings: []string{"line 2: weight 0.5 of arc 0 -b-> 1 is dropped", "line 5: final weight 2.5 of state 1 is dropped"},
+		},
+		{
+			name:         "transducer output labels are reported",
+			data:         "0 0 a a\n0 1 b a 1\n1 0 a a 0\n1 1 b b\n1\n",
+			wantWarnings: []string{"line 2: output label a of arc 0 -b-> 1 is dropped", "line 2: weight 1 of arc 0 -b-> 1 is dropped"},
+		},
+		{
+			// output label 2 is "b" in the input table, but "a" in the output table: every arc outputs its input, nothing is dropped
+			name:    "transducer with its own output symbols",
+			data:    "0 0 1 2\n0 1 2 1\n1 0 1 2\n1 1 2 1\n1\n",
+			options: ATTOptions{NumericLabels: true, InputSymbols: symbols, OutputSymbols: SymbolTable{ATTEpsilon: 0, "b": 1, "a": 2}},
+		},
+		{
+			name:    "infinite final weight is not final",
+			data:    "0 0 a\n0 1 b\n1 0 a\n1 1 b\n1\n0 Infinity\n",
+			options: ATTOptions{Acceptor: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fa, warnings, err := ReadATT(strings.NewReader(tt.data), tt.options)
+			if err != nil {
+				t.Fatalf("ReadATT() error = %v", err)
+			}
+			if !fa.Equals(endsInOneFA) {
+				t.Errorf("ReadATT() = %v, want %v", fa, endsInOneFA)
+			}
+			var got []string
+			for _, w := range warnings {
+				got = append(got, w.String())
+			}
+			if !reflect.DeepEqual(got, tt.wantWarnings) {
+				t.Errorf("ReadATT() warnings = %v, want %v", got, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestReadATT_Errors(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		data    string
+		options ATTOptions
+		wantErr string
+	}{
+		{name: "empty", data: "\n", wantErr: "AT&T file is empty"},
+		{name: "too many fields", data: "0 1 a a 0 x\n", wantErr: "line 1: expected at most 5 fields"},
+		{name: "epsilon label", data: "0 1 0\n1\n", options: ATTOptions{NumericLabels: true}, wantErr: "line 1: ε transition"},
+		{name: "epsilon symbol", data: "0 1 <eps>\n1\n", wantErr: "line 1: ε transition"},
+		{name: "nondeterministic", data: "0 0 a\n0 1 a\n1\n", wantErr: "line 2: state 0 has arcs on a to both 0 and 1"},
+		{name: "incomplete", data: "0 1 a\n1\n", wantErr: "state 1 has no arc on a"},
+		{name: "unknown label", data: "0 0 7\n0\n", options: ATTOptions{NumericLabels: true, InputSymbols: SymbolTable{"a": 1}}, wantErr: "line 1: label 7 is not in the input symbol table"},
+		{name: "symbol of label 0", data: "0 0 c\n0\n", options: ATTOptions{InputSymbols: SymbolTable{"c": 0, "a": 1}}, wantErr: "line 1: ε transition"},
+		{name: "symbol not in table", data: "0 0 b\n0\n", options: ATTOptions{InputSymbols: SymbolTable{"a": 1}}, wantErr: "line 1: label b is not in the input symbol table"},
+		{name: "label is not a number", data: "0 0 a\n0\n", options: ATTOptions{NumericLabels: true}, wantErr: "line 1: label a is not a non-negative integer"},
+		{name: "numeric state not in table", data: "0 3 1\n0\n", options: ATTOptions{NumericLabels: true, StateSymbols: SymbolTable{"start": 0}}, wantErr: "line 1: state 3 is not in the state symbol table"},
+		{name: "unknown state", data: "0 0 a\n0\n", options: ATTOptions{StateSymbols: SymbolTable{"start": 1}}, wantErr: "line 1: state 0 is not in the state symbol table"},
+		{name: "no final state", data: "0 0 a\n", wantErr: "F(list of acceptable final states) is empty"},
+		{name: "bad final weight", data: "0 0 a\n0 a\n", wantErr: "line 2: weight a is not a number"},
+		{name: "bad arc weight", data: "0 0 a a x\n0\n", wantErr: "line 1: weight x is not a number"},
+		{name: "unknown output label", data: "0 0 1 7\n0\n", options: ATTOptions{NumericLabels: true, InputSymbols: SymbolTable{"a": 1}, OutputSymbols: SymbolTable{"b": 1}}, wantErr: "line 1: output label 7 is not in the output symbol table"},
+		{name: "only infinite final weights", data: "0 0 a\n0 inf\n", wantErr: "F(list of acceptable final states) is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ReadATT(strings.NewReader(tt.data), tt.options)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadATT() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFiniteAutomaton_WriteATT(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S1", NewSet("S0"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+
+	var b strings.Builder
+	if err := threeModFA.WriteATT(&b); err != nil {
+		t.Fatalf("WriteATT() error = %v", err)
+	}
+	want := "S1\tS2\t0\nS1\tS0\t1\nS0\tS0\t0\nS0\tS1\t1\nS2\tS1\t0\nS2\tS2\t1\nS0\n"
+	if b.String() != want {
+		t.Errorf("WriteATT() = \n%s\n want \n%s", b.String(), want)
+	}
+	states, inputs, err := threeModFA.ATTSymbols()
+	if err != nil {
+		t.Fatalf("ATTSymbols() error = %v", err)
+	}
+	for _, options := range []ATTOptions{{}, {InputSymbols: inputs, StateSymbols: states}} {
+		fa, warnings, err := ReadATT(strings.NewReader(b.String()), options)
+		if err != nil || len(warnings) != 0 || !fa.Equals(threeModFA) {
+			t.Errorf("ReadATT() of WriteATT() = %v, %v, %v, want %v", fa, warnings, err, threeModFA)
+		}
+	}
+
+	// the same FA as fstprint writes it without symbol tables: label 1 is input "0" and label 2 is input "1", never the other way around
+	numeric := "0\t2\t1\n0\t1\t2\n1\t1\t1\n1\t0\t2\n2\t0\t1\n2\t2\t2\n1\n"
+	fa, warnings, err := ReadATT(strings.NewReader(numeric), ATTOptions{NumericLabels: true, InputSymbols: inputs, StateSymbols: states})
+	if err != nil || len(warnings) != 0 || !fa.Equals(threeModFA) {
+		t.Errorf("ReadATT() of numeric labels = %v, %v, %v, want %v", fa, warnings, err, threeModFA)
+	}
+	if want := (SymbolTable{"S1": 0, "S0": 1, "S2": 2}); !reflect.DeepEqual(states, want) {
+		t.Errorf("ATTSymbols() states = %v, want %v", states, want)
+	}
+	if want := (SymbolTable{ATTEpsilon: 0, "0": 1, "1": 2}); !reflect.DeepEqual(inputs, want) {
+		t.Errorf("ATTSymbols() inputs = %v, want %v", inputs, want)
+	}
+
+	spaced, _ := NewFiniteAutomaton(NewSet("S 0"), NewSet("a"), "S 0", NewSet("S 0"), map[string]map[string]string{"S 0": {"a": "S 0"}})
+	if err := spaced.WriteATT(&b); err == nil {
+		t.Errorf("WriteATT() expected error on a state name with a space")
+	}
+}
+
+func TestSymbolTable(t *testing.T) {
+
+	table := SymbolTable{ATTEpsilon: 0, "a": 1, "b": 2}
+	var b strings.Builder
+	if err := table.Write(&b); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if want := "<eps>\t0\na\t1\nb\t2\n"; b.String() != want {
+		t.Errorf("Write() = %q, want %q", b.String(), want)
+	}
+	got, err := ReadSymbolTable(strings.NewReader(b.String()))
+	if err != nil || !reflect.DeepEqual(got, table) {
+		t.Errorf("ReadSymbolTable() = %v, %v, want %v", got, err, table)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "missing label", data: "a\n", wantErr: "line 1: expected symbol and label"},
+		{name: "bad label", data: "a x\n", wantErr: "line 1: label x of symbol a"},
+		{name: "duplicate symbol", data: "a 1\na 2\n", wantErr: "line 2: symbol a is defined twice"},
+		{name: "duplicate label", data: "a 1\nb 1\n", wantErr: "line 2: label 1 is used by both a and b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadSymbolTable(strings.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadSymbolTable() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
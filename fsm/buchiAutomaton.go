@@ -0,0 +1,286 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BuchiAutomaton represents a nondeterministic Büchi automaton over infinite words (ω-words).
+//
+//	An infinite run is accepting if it visits a state of F infinitely often. Delta doesn't need to be total: a missing
+//	transition just ends the run, which is then rejected
+type BuchiAutomaton struct {
+	Q       Set[string]                       // Q is the set of states
+	Sigma   Set[string]                       // Sigma is the set of inputs
+	Initial Set[string]                       // Initial is the set of initial states
+	F       Set[string]                       // F is the set of accepting states
+	Delta   map[string]map[string]Set[string] // Delta is a map between state, input and the set of next states
+}
+
+// NewBuchiAutomaton creates a new BuchiAutomaton. Does initial error checking as well.
+func NewBuchiAutomaton(Q Set[string], Sigma Set[string], initial Set[string], F Set[string], Delta map[string]map[string]Set[string]) (*BuchiAutomaton, error) {
+	if len(Q) == 0 {
+		return nil, fmt.Errorf("Q(list of acceptable states) is empty")
+	}
+	if len(Sigma) == 0 {
+		return nil, fmt.Errorf("Σ(Sigma)=(list of acceptable input) is empty")
+	}
+	if len(initial) == 0 {
+		return nil, fmt.Errorf("no initial state")
+	}
+	if !initial.IsSubset(Q) {
+		return nil, fmt.Errorf("initial states are not a subset of Q")
+	}
+	if !F.IsSubset(Q) {
+		return nil, fmt.Errorf("F(list of accepting states) is not a subset of Q")
+	}
+	ba := BuchiAutomaton{Q: Q.DeepCopy(), Sigma: Sigma.DeepCopy(), Initial: initial.DeepCopy(), F: F.DeepCopy(), Delta: make(map[string]map[string]Set[string], len(Delta))}
+
+	// deep copy Delta and error check in the process
+	for state, bySymbol := range Delta {
+		if !ba.Q.Contains(state) {
+			return nil, fmt.Errorf("delta state %s is not one of the acceptable states", state)
+		}
+		ba.Delta[state] = make(map[string]Set[string], len(bySymbol))
+		for symbol, next := range bySymbol {
+			if !ba.Sigma.Contains(symbol) {
+				return nil, fmt.Errorf("delta input %s of state %s is not an acceptable input", symbol, state)
+			}
+			if !next.IsSubset(ba.Q) {
+				return nil, fmt.Errorf("delta of state %s on input %s goes to states that are not in Q", state, symbol)
+			}
+			ba.Delta[state][symbol] = next.DeepCopy()
+		}
+	}
+	return &ba, nil
+}
+
+// ToBuchi returns the deterministic Büchi automaton with the same tuple as the FA: an infinite word is accepted if its run
+// visits F infinitely often
+func (f *FiniteAutomaton) ToBuchi() *BuchiAutomaton {
+	delta := make(map[string]map[string]Set[string], len(f.Delta))
+	for state, bySymbol := range f.Delta {
+		delta[state] = make(map[string]Set[string], len(bySymbol))
+		for symbol, next := range bySymbol {
+			delta[state][symbol] = NewSet(next)
+		}
+	}
+	return &BuchiAutomaton{Q: f.Q.DeepCopy(), Sigma: f.Sigma.DeepCopy(), Initial: NewSet(f.q0), F: f.F.DeepCopy(), Delta: delta}
+}
+
+// String returns a string representing the BuchiAutomaton as a string
+func (b *BuchiAutomaton) String() string {
+	return fmt.Sprintf("NBA:\n\tQ=%s\n\tΣ=%s\n\tI=%s\n\tF=%s\n\tδ=%v\n", b.Q.String(), b.Sigma.String(), b.Initial.String(), b.F.String(), b.Delta)
+}
+
+// splitSymbols splits a word into its runes, checking that each of them is in Sigma
+func (b *BuchiAutomaton) splitSymbols(word string) ([]string, error) {
+	var symbols []string
+	for _, r := range word {
+		if !b.Sigma.Contains(string(r)) {
+			return nil, fmt.Errorf("rune %v is not an acceptable input", string(r))
+		}
+		symbols = append(symbols, string(r))
+	}
+	return symbols, nil
+}
+
+// AcceptsLasso reports whether the ultimately periodic word prefix·loop^ω is accepted. loop can't be empty
+func (b *BuchiAutomaton) AcceptsLasso(prefix string, loop string) (bool, error) {
+	prefixSymbols, err := b.splitSymbols(prefix)
+	if err != nil {
+		return false, err
+	}
+	loopSymbols, err := b.splitSymbols(loop)
+	if err != nil {
+		return false, err
+	}
+	if len(loopSymbols) == 0 {
+		return false, fmt.Errorf("loop of a lasso word can't be empty")
+	}
+
+	// states reachable after the prefix
+	current := b.Initial.DeepCopy()
+	for _, symbol := range prefixSymbols {
+		next := NewSet[string]()
+		for state := range current {
+			for to := range b.Delta[state][symbol] {
+				next.Add(to)
+			}
+		}
+		current = next
+	}
+
+	// run the loop on the graph of (state, position in loop) nodes, accept if an accepting node reachable from the
+	// start of the loop lies on a cycle
+	type node struct {
+		state    string
+		position int
+	}
+	successors := func(n node) []node {
+		var next []node
+		for _, to := range sortedStrings(b.Delta[n.state][loopSymbols[n.position]]) {
+			next = append(next, node{to, (n.position + 1) % len(loopSymbols)})
+		}
+		return next
+	}
+	reach := func(starts []node) map[node]bool {
+		seen := make(map[node]bool)
+		stack := append([]node(nil), starts...)
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+			stack = append(stack, successors(n)...)
+		}
+		return seen
+	}
+
+	var starts []node
+	for _, state := range sortedStrings(current) {
+		starts = append(starts, node{state, 0})
+	}
+	for n := range reach(starts) {
+		if b.F.Contains(n.state) && reach(successors(n))[n] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// buchiEdge is the edge a search used to first reach a state
+type buchiEdge struct {
+	from   string
+	symbol string
+}
+
+// IsEmpty reports whether the automaton accepts no infinite word. If it isn't empty, it also returns a witness lasso word
+// prefix·loop^ω that is accepted.
+//
+//	Uses a nested search: an outer search for the reachable accepting states, then an inner one looking for a cycle back to each of them
+func (b *BuchiAutomaton) IsEmpty() (bool, string, string) {
+	sigma := sortedStrings(b.Sigma)
+
+	// outer search: visit states reachable from the initial states, remembering how we got there
+	parent := make(map[string]buchiEdge)
+	queue := sortedStrings(b.Initial)
+	visited := b.Initial.DeepCopy()
+	var reachable []string
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		reachable = append(reachable, state)
+		for _, symbol := range sigma {
+			for _, to := range sortedStrings(b.Delta[state][symbol]) {
+				if !visited.Contains(to) {
+					visited.Add(to)
+					parent[to] = buchiEdge{state, symbol}
+					queue = append(queue, to)
+				}
+			}
+		}
+	}
+
+	// inner search: for each reachable accepting state, look for a non empty path back to itself
+	for _, seed := range reachable {
+		if !b.F.Contains(seed) {
+			continue
+		}
+		innerParent := make(map[string]buchiEdge)
+		var closing *buchiEdge
+		inner := []string{seed}
+		seen := NewSet[string]()
+		for len(inner) > 0 && closing == nil {
+			state := inner[0]
+			inner = inner[1:]
+			for _, symbol := range sigma {
+				for _, to := range sortedStrings(b.Delta[state][symbol]) {
+					if to == seed && closing == nil {
+						closing = &buchiEdge{state, symbol}
+					}
+					if !seen.Contains(to) && to != seed {
+						seen.Add(to)
+						innerParent[to] = buchiEdge{state, symbol}
+						inner = append(inner, to)
+					}
+				}
+			}
+		}
+		if closing == nil {
+			continue
+		}
+
+		// rebuild the loop backwards from the closing edge, and the prefix backwards from seed
+		loop := []string{closing.symbol}
+		for state := closing.from; state != seed; state = innerParent[state].from {
+			loop = append([]string{innerParent[state].symbol}, loop...)
+		}
+		var prefix []string
+		for state := seed; ; {
+			e, exists := parent[state]
+			if !exists {
+				break
+			}
+			prefix = append([]string{e.symbol}, prefix...)
+			state = e.from
+		}
+		return false, strings.Join(prefix, ""), strings.Join(loop, "")
+	}
+	return true, "", ""
+}
+
+// Product returns the Büchi automaton of the runs of system that satisfy b, for checking system against the property b.
+//
+//	system runs forever, so all its states count as accepting: system's F is ignored. States of the product are the pairs "(s,q)" of a system state
+//	and a state of b, reachable from the initial pairs. A pair is accepting if q is in b's F. Inputs are the inputs of system,
+//	which must all be inputs of b: a run on any other input would be missing from the product.
+//	To verify that every run of system satisfies a property, take b as the automaton of the negated property and check IsEmpty
+func (b *BuchiAutomaton) Product(system *FiniteAutomaton) (*BuchiAutomaton, error) {
+	type pair struct{ s, q string }
+
+	symbols := sortedStrings(system.Sigma)
+	for _, symbol := range symbols {
+		if !b.Sigma.Contains(symbol) {
+			return nil, fmt.Errorf("input %s of the system is not an input of the Büchi automaton", symbol)
+		}
+	}
+
+	Q := NewSet[string]()
+	F := NewSet[string]()
+	initial := NewSet[string]()
+	delta := make(map[string]map[string]Set[string])
+	var queue []pair
+	for _, q := range sortedStrings(b.Initial) {
+		start := pair{system.q0, q}
+		initial.Add(tupleState(start.s, start.q))
+		Q.Add(tupleState(start.s, start.q))
+		queue = append(queue, start)
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		name := tupleState(current.s, current.q)
+		if b.F.Contains(current.q) {
+			F.Add(name)
+		}
+		delta[name] = make(map[string]Set[string])
+		for _, symbol := range symbols {
+			s := system.Delta[current.s][symbol]
+			for _, q := range sortedStrings(b.Delta[current.q][symbol]) {
+				next := tupleState(s, q)
+				if delta[name][symbol] == nil {
+					delta[name][symbol] = NewSet[string]()
+				}
+				delta[name][symbol].Add(next)
+				if !Q.Contains(next) {
+					Q.Add(next)
+					queue = append(queue, pair{s, q})
+				}
+			}
+		}
+	}
+	return NewBuchiAutomaton(Q, system.Sigma, initial, F, delta)
+}
@@ -0,0 +1,131 @@
+package fsm
+
+import (
+	"strings"
+	"testing"
+)
+
+// newInfinitelyOftenA returns a deterministic Büchi automaton over (a, b) accepting words with infinitely many "a"
+func newInfinitelyOftenA(t *testing.T) *BuchiAutomaton {
+	fa, err := NewFiniteAutomaton(
+		NewSet("seenA", "seenB"),
+		NewSet("a", "b"), "seenB", NewSet("seenA"),
+		map[string]map[string]string{
+			"seenA": {"a": "seenA", "b": "seenB"},
+			"seenB": {"a": "seenA", "b": "seenB"},
+		})
+	if err != nil {
+		t.Fatalf("NewFiniteAutomaton() error = %v", err)
+	}
+	return fa.ToBuchi()
+}
+
+// newEventuallyAlwaysB returns a nondeterministic Büchi automaton over (a, b) accepting words with finitely many "a"
+func newEventuallyAlwaysB(t *testing.T) *BuchiAutomaton {
+	ba, err := NewBuchiAutomaton(
+		NewSet("any", "onlyB"), NewSet("a", "b"), NewSet("any"), NewSet("onlyB"),
+		map[string]map[string]Set[string]{
+			"any":   {"a": NewSet("any"), "b": NewSet("any", "onlyB")}, // guess when the last "a" was seen
+			"onlyB": {"b": NewSet("onlyB")},
+		})
+	if err != nil {
+		t.Fatalf("NewBuchiAutomaton() error = %v", err)
+	}
+	return ba
+}
+
+func TestBuchiAutomaton_AcceptsLasso(t *testing.T) {
+
+	infinitelyOftenA := newInfinitelyOftenA(t)
+	eventuallyAlwaysB := newEventuallyAlwaysB(t)
+
+	tests := []struct {
+		prefix string
+		loop   string
+		wantA  bool // accepted by infinitelyOftenA
+		wantB  bool // accepted by eventuallyAlwaysB
+	}{
+		{prefix: "", loop: "a", wantA: true, wantB: false},
+		{prefix: "aaa", loop: "b", wantA: false, wantB: true},
+		{prefix: "b", loop: "ab", wantA: true, wantB: false},
+		{prefix: "abab", loop: "bbb", wantA: false, wantB: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix+"("+tt.loop+")", func(t *testing.T) {
+			if got, err := infinitelyOftenA.AcceptsLasso(tt.prefix, tt.loop); err != nil || got != tt.wantA {
+				t.Errorf("infinitelyOftenA.AcceptsLasso() = %v, %v, want %v", got, err, tt.wantA)
+			}
+			if got, err := eventuallyAlwaysB.AcceptsLasso(tt.prefix, tt.loop); err != nil || got != tt.wantB {
+				t.Errorf("eventuallyAlwaysB.AcceptsLasso() = %v, %v, want %v", got, err, tt.wantB)
+			}
+		})
+	}
+
+	if _, err := infinitelyOftenA.AcceptsLasso("a", ""); err == nil {
+		t.Errorf("AcceptsLasso() expected error for an empty loop")
+	}
+	if _, err := infinitelyOftenA.AcceptsLasso("c", "a"); err == nil {
+		t.Errorf("AcceptsLasso() expected error for a bad input")
+	}
+}
+
+func TestBuchiAutomaton_IsEmpty(t *testing.T) {
+
+	eventuallyAlwaysB := newEventuallyAlwaysB(t)
+	empty, prefix, loop := eventuallyAlwaysB.IsEmpty()
+	if empty {
+		t.Fatalf("IsEmpty() = true, want false")
+	}
+	if accepted, err := eventuallyAlwaysB.AcceptsLasso(prefix, loop); err != nil || !accepted {
+		t.Errorf("IsEmpty() witness %s(%s) is not accepted", prefix, loop)
+	}
+
+	// the accepting state is reachable but not on a cycle
+	noCycle, _ := NewBuchiAutomaton(
+		NewSet("S0", "S1", "S2"), NewSet("a"), NewSet("S0"), NewSet("S1"),
+		map[string]map[string]Set[string]{
+			"S0": {"a": NewSet("S1")},
+			"S1": {"a": NewSet("S2")},
+			"S2": {"a": NewSet("S2")},
+		})
+	if empty, _, _ := noCycle.IsEmpty(); !empty {
+		t.Errorf("IsEmpty() = false, want true")
+	}
+}
+
+func TestBuchiAutomaton_Product(t *testing.T) {
+
+	// a protocol that alternates request ("a") and idle ("b") can't stop requesting, so its alternating runs violate
+	// "eventually always idle". Only the runs that fall into the error state satisfy it
+	alternating, _ := NewFiniteAutomaton(
+		NewSet("idle", "busy", "error"),
+		NewSet("a", "b"), "idle", NewSet("idle", "busy"),
+		map[string]map[string]string{
+			"idle":  {"a": "busy", "b": "error"},
+			"busy":  {"a": "error", "b": "idle"},
+			"error": {"a": "error", "b": "error"},
+		})
+	eventuallyAlwaysB := newEventuallyAlwaysB(t)
+
+	product, err := eventuallyAlwaysB.Product(alternating)
+	if err != nil {
+		t.Fatalf("BuchiAutomaton.Product() error = %v", err)
+	}
+	// the error state allows "b" forever, so the product isn't empty and the witness goes through error
+	empty, prefix, loop := product.IsEmpty()
+	if empty {
+		t.Fatalf("product.IsEmpty() = true, want false")
+	}
+	fsm := alternating.NewFiniteStateMachine()
+	fsm.ProcessReader(strings.NewReader(prefix + loop))
+	if fsm.CurrentState() != "error" {
+		t.Errorf("witness %s(%s) should drive the protocol into error, got %s", prefix, loop, fsm.CurrentState())
+	}
+
+	// runs on an input the property doesn't know would be dropped from the product
+	withReset, _ := NewFiniteAutomaton(NewSet("idle"), NewSet("a", "b", "reset"), "idle", NewSet("idle"),
+		map[string]map[string]string{"idle": {"a": "idle", "b": "idle", "reset": "idle"}})
+	if _, err := eventuallyAlwaysB.Product(withReset); err == nil || !strings.Contains(err.Error(), "input reset of the system") {
+		t.Errorf("BuchiAutomaton.Product() error = %v, want an error for input reset", err)
+	}
+}
@@ -0,0 +1,134 @@
+package fsm
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// contextCheckInterval is how many runes are processed between two checks of ctx.Done()
+const contextCheckInterval = 64
+
+// InterruptedError is returned by the context-aware APIs when ctx is cancelled or its deadline passes before the input is consumed.
+//
+//	It unwraps to ctx.Err(), so errors.Is(err, context.DeadlineExceeded) works as expected
+type InterruptedError struct {
+	Position int    // Position is the number of runes processed before the interruption
+	State    string // State is the state the FSM was in when interrupted
+	Err      error  // Err is ctx.Err()
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("processing interrupted at position %d in state %s: %v", e.Position, e.State, e.Err)
+}
+
+func (e *InterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// GetFSMOutputContext is GetFSMOutput that stops when ctx is done, returning an *InterruptedError with the position reached.
+//
+//	Errors from ProcessInputRune and the final state check are returned unchanged
+func (f *FiniteStateMachine) GetFSMOutputContext(ctx context.Context, input string) (int, error) {
+	position := 0
+	for _, r := range input {
+		if err := f.checkContext(ctx, position); err != nil {
+			return 0, err
+		}
+		if err := f.ProcessInputRune(string(r)); err != nil {
+			return 0, err
+		}
+		position++
+	}
+	return f.output()
+}
+
+// ProcessReader feeds every rune of r to the FSM until io.EOF and returns the number of runes processed.
+//
+//	Call Output afterwards to check the final state and convert it, like GetFSMOutput does
+func (f *FiniteStateMachine) ProcessReader(r io.RuneReader) (int, error) {
+	return f.ProcessReaderContext(context.Background(), r)
+}
+
+// ProcessReaderContext is ProcessReader that stops when ctx is done, returning an *InterruptedError with the position reached
+func (f *FiniteStateMachine) ProcessReaderContext(ctx context.Context, r io.RuneReader) (int, error) {
+	return f.processReader(ctx, r, f.ProcessInputRune)
+}
+
+// processReader feeds every rune of r to process until io.EOF, checking ctx on the way, and returns the number of runes processed
+func (f *FiniteStateMachine) processReader(ctx context.Context, r io.RuneReader, process func(string) error) (int, error) {
+	position := 0
+	for {
+		if err := f.checkContext(ctx, position); err != nil {
+			return position, err
+		}
+		inputRune, _, err := r.ReadRune()
+		if err == io.EOF {
+			return position, nil
+		}
+		if err != nil {
+			return position, fmt.Errorf("could not read input at position %d. Error: %w", position, err)
+		}
+		if err := process(string(inputRune)); err != nil {
+			return position, err
+		}
+		position++
+	}
+}
+
+// Output returns the output of the current state, or an error if it is not one of the accepted final states
+func (f *FiniteStateMachine) Output() (int, error) {
+	return f.output()
+}
+
+// checkContext returns an *InterruptedError if ctx is done. ctx is only checked every contextCheckInterval runes
+func (f *FiniteStateMachine) checkContext(ctx context.Context, position int) error {
+	if position%contextCheckInterval != 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return &InterruptedError{Position: position, State: f.currentState, Err: ctx.Err()}
+	default:
+		return nil
+	}
+}
+
+// GetFSMOutputContext is GetFSMOutput that stops when ctx is done. See FiniteStateMachine.GetFSMOutputContext
+func (s *SyncFiniteStateMachine) GetFSMOutputContext(ctx context.Context, input string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	position := 0
+	for _, r := range input {
+		if err := s.fsm.checkContext(ctx, position); err != nil {
+			return 0, err
+		}
+		if err := s.processInputRune(string(r)); err != nil {
+			return 0, err
+		}
+		position++
+	}
+	return s.fsm.output()
+}
+
+// ProcessReader feeds every rune of r to the machine under its lock. See FiniteStateMachine.ProcessReader
+func (s *SyncFiniteStateMachine) ProcessReader(r io.RuneReader) (int, error) {
+	return s.ProcessReaderContext(context.Background(), r)
+}
+
+// ProcessReaderContext is ProcessReader that stops when ctx is done. See FiniteStateMachine.ProcessReaderContext.
+//
+//	The lock is held until the reader is consumed or processing stops, so no other goroutine can interleave transitions
+func (s *SyncFiniteStateMachine) ProcessReaderContext(ctx context.Context, r io.RuneReader) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fsm.processReader(ctx, r, s.processInputRune)
+}
+
+// Output returns the output of the current state, or an error if it is not one of the accepted final states
+func (s *SyncFiniteStateMachine) Output() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fsm.output()
+}
@@ -0,0 +1,134 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFiniteStateMachine_GetFSMOutputContext(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		input      string
+		want       int
+		wantErr    error
+		wantAnyErr bool
+	}{
+		{name: "not cancelled", ctx: context.Background(), input: "1101", want: 1},
+		{name: "cancelled", ctx: cancelled, input: "1101", wantErr: context.Canceled},
+		{name: "deadline exceeded", ctx: expired, input: "1101", wantErr: context.DeadlineExceeded},
+		{name: "bad input still reported", ctx: context.Background(), input: "1102", wantAnyErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsm := threeModFA.NewFiniteStateMachine()
+			got, err := fsm.GetFSMOutputContext(tt.ctx, tt.input)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GetFSMOutputContext() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if (err != nil) != tt.wantAnyErr {
+				t.Fatalf("GetFSMOutputContext() error = %v, wantErr %v", err, tt.wantAnyErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetFSMOutputContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFiniteStateMachine_ProcessReaderContext(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+
+	// cancel from a hook once 100 runes have been processed, the FSM should stop at the next check
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fsm := threeModFA.NewFiniteStateMachine()
+	processed := 0
+	fsm.OnTransitionAny(func(symbol string, from string, to string) error {
+		processed++
+		if processed == 100 {
+			cancel()
+		}
+		return nil
+	})
+
+	position, err := fsm.ProcessReaderContext(ctx, strings.NewReader(strings.Repeat("1", 1000)))
+	var interrupted *InterruptedError
+	if !errors.As(err, &interrupted) {
+		t.Fatalf("ProcessReaderContext() error = %v, want *InterruptedError", err)
+	}
+	if position != interrupted.Position || position != 2*contextCheckInterval {
+		t.Errorf("ProcessReaderContext() position = %v, InterruptedError.Position = %v, want %v", position, interrupted.Position, 2*contextCheckInterval)
+	}
+
+	// a full read followed by Output works like GetFSMOutput
+	fsm = threeModFA.NewFiniteStateMachine()
+	position, err = fsm.ProcessReader(strings.NewReader("1101"))
+	if err != nil || position != 4 {
+		t.Fatalf("ProcessReader() = %v, %v, want 4, nil", position, err)
+	}
+	if got, err := fsm.Output(); err != nil || got != 1 {
+		t.Errorf("Output() = %v, %v, want 1", got, err)
+	}
+}
+
+func TestSyncFiniteStateMachine_ProcessReaderContext(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+
+	s := threeModFA.NewSyncFiniteStateMachine()
+	changes, cancelSubscription := s.Subscribe(4)
+	defer cancelSubscription()
+	position, err := s.ProcessReader(strings.NewReader("110"))
+	if err != nil || position != 3 {
+		t.Fatalf("ProcessReader() = %v, %v, want 3, nil", position, err)
+	}
+	if got, err := s.Output(); err != nil || got != 0 || s.CurrentState() != "S0" {
+		t.Errorf("Output() = %v, %v in state %v, want 0 in S0", got, err, s.CurrentState())
+	}
+	if len(changes) != 3 {
+		t.Errorf("Subscribe() received %d changes, want 3", len(changes))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.ProcessReaderContext(ctx, strings.NewReader("1")); !errors.Is(err, context.Canceled) {
+		t.Errorf("ProcessReaderContext() error = %v, want %v", err, context.Canceled)
+	}
+}
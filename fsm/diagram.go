@@ -0,0 +1,162 @@
+package fsm
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiagramOptions configures the Mermaid and PlantUML state diagrams of a FiniteAutomaton
+type DiagramOptions struct {
+	Trace *Trace // Trace, if not nil, highlights the states visited and the transitions taken by a FiniteStateMachine run
+}
+
+// diagramEdge is a transition of a diagram, with the inputs of parallel transitions merged in one label
+type diagramEdge struct {
+	from, to string
+	label    string // label is the comma separated inputs, quoted like in the text format
+}
+
+// diagramEdges returns the transitions of the FA merged by source and target, sorted by source then target
+func (f *FiniteAutomaton) diagramEdges() []diagramEdge {
+	var edges []diagramEdge
+	for _, from := range sortedStrings(f.Q) {
+		labels := make(map[string][]string)
+		for _, input := range sortedStrings(f.Sigma) {
+			to := f.Delta[from][input]
+			labels[to] = append(labels[to], quoteElement(input))
+		}
+		targets := make([]string, 0, len(labels))
+		for to := range labels {
+			targets = append(targets, to)
+		}
+		sort.Strings(targets)
+		for _, to := range targets {
+			edges = append(edges, diagramEdge{from: from, to: to, label: strings.Join(labels[to], ", ")})
+		}
+	}
+	return edges
+}
+
+// diagramHighlight is what a trace highlights in a diagram
+type diagramHighlight struct {
+	visited Set[string]         // visited are the states the run went through
+	steps   map[[2]string][]int // steps maps a (from, to) edge to the positions of the steps that took it
+}
+
+// highlight checks the trace of options against the FA and returns what it highlights. Nothing is highlighted without a trace
+func (f *FiniteAutomaton) highlight(options DiagramOptions) (diagramHighlight, error) {
+	h := diagramHighlight{visited: NewSet[string](), steps: make(map[[2]string][]int)}
+	if options.Trace == nil {
+		return h, nil
+	}
+	for i, step := range options.Trace.Steps {
+		if to, exists := f.Delta[step.From][step.Symbol]; !exists || to != step.To {
+			return h, fmt.Errorf("trace step %d (%s -%s-> %s) is not a transition of the FA", i, step.From, step.Symbol, step.To)
+		}
+		h.visited.Add(step.From)
+		h.visited.Add(step.To)
+		edge := [2]string{step.From, step.To}
+		h.steps[edge] = append(h.steps[edge], step.Position)
+	}
+	return h, nil
+}
+
+// diagramIDs names the states s0, s1, ... in sorted order, as diagram languages only accept simple identifiers
+func (f *FiniteAutomaton) diagramIDs() ([]string, map[string]string) {
+	states := sortedStrings(f.Q)
+	ids := make(map[string]string, len(states))
+	for i, state := range states {
+		ids[state] = "s" + strconv.Itoa(i)
+	}
+	return states, ids
+}
+
+// mermaidEscape replaces the characters that end or break a Mermaid label with entity codes
+var mermaidEscape = strings.NewReplacer(`#`, `#35;`, `"`, `#quot;`, `:`, `#58;`, `;`, `#59;`, `<`, `#lt;`, `>`, `#gt;`, "\n", ` `)
+
+// WriteMermaid writes the FA as a Mermaid stateDiagram-v2, to embed in Markdown. The output is deterministic.
+//
+//	With a trace, visited states get the "visited" class and the labels of taken transitions list the positions of their steps,
+//	as Mermaid state diagrams can't style transitions
+func (f *FiniteAutomaton) WriteMermaid(w io.Writer, options DiagramOptions) error {
+	h, err := f.highlight(options)
+	if err != nil {
+		return err
+	}
+	states, ids := f.diagramIDs()
+	var b strings.Builder
+	b.WriteString("stateDiagram-v2\n    direction LR\n")
+	for _, state := range states {
+		fmt.Fprintf(&b, "    state \"%s\" as %s\n", mermaidEscape.Replace(state), ids[state])
+	}
+	fmt.Fprintf(&b, "    [*] --> %s\n", ids[f.q0])
+	for _, e := range f.diagramEdges() {
+		label := e.label
+		if positions, taken := h.steps[[2]string{e.from, e.to}]; taken {
+			label += " (steps " + joinInts(positions) + ")"
+		}
+		fmt.Fprintf(&b, "    %s --> %s : %s\n", ids[e.from], ids[e.to], mermaidEscape.Replace(label))
+	}
+	for _, state := range sortedStrings(f.F) {
+		fmt.Fprintf(&b, "    %s --> [*]\n", ids[state])
+	}
+	if h.visited.Size() > 0 {
+		b.WriteString("    classDef visited fill:#ffd54f,stroke:#e65100,stroke-width:2px\n")
+		visited := make([]string, 0, h.visited.Size())
+		for _, state := range sortedStrings(h.visited) {
+			visited = append(visited, ids[state])
+		}
+		fmt.Fprintf(&b, "    class %s visited\n", strings.Join(visited, ","))
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// plantUMLEscape escapes the characters that end or break a PlantUML label
+var plantUMLEscape = strings.NewReplacer(`"`, `~"`, "\n", `\n`)
+
+// WritePlantUML writes the FA as a PlantUML state diagram, from @startuml to @enduml. The output is deterministic.
+//
+//	With a trace, visited states are filled and taken transitions are drawn bold and colored
+func (f *FiniteAutomaton) WritePlantUML(w io.Writer, options DiagramOptions) error {
+	h, err := f.highlight(options)
+	if err != nil {
+		return err
+	}
+	states, ids := f.diagramIDs()
+	var b strings.Builder
+	b.WriteString("@startuml\nhide empty description\nleft to right direction\n")
+	for _, state := range states {
+		fmt.Fprintf(&b, "state \"%s\" as %s", plantUMLEscape.Replace(state), ids[state])
+		if h.visited.Contains(state) {
+			b.WriteString(" #FFD54F")
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "[*] --> %s\n", ids[f.q0])
+	for _, e := range f.diagramEdges() {
+		arrow := "-->"
+		if _, taken := h.steps[[2]string{e.from, e.to}]; taken {
+			arrow = "-[#E65100,bold]->"
+		}
+		fmt.Fprintf(&b, "%s %s %s : %s\n", ids[e.from], arrow, ids[e.to], plantUMLEscape.Replace(e.label))
+	}
+	for _, state := range sortedStrings(f.F) {
+		fmt.Fprintf(&b, "%s --> [*]\n", ids[state])
+	}
+	b.WriteString("@enduml\n")
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// joinInts returns the ints as "1,2,3"
+func joinInts(ints []int) string {
+	s := make([]string, len(ints))
+	for i, n := range ints {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ",")
+}
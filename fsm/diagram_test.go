@@ -0,0 +1,134 @@
+package fsm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFiniteAutomaton_WriteMermaid(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+	fsm := threeModFA.NewFiniteStateMachine()
+	fsm.Tracer = NewTrace()
+	for _, r := range "110" {
+		_ = fsm.ProcessInputRune(string(r))
+	}
+
+	tests := []struct {
+		name    string
+		options DiagramOptions
+		want    string
+	}{
+		{
+			name:    "no trace",
+			options: DiagramOptions{},
+			want: `stateDiagram-v2
+    direction LR
+    state "S0" as s0
+    state "S1" as s1
+    state "S2" as s2
+    [*] --> s0
+    s0 --> s0 : 0
+    s0 --> s1 : 1
+    s1 --> s0 : 1
+    s1 --> s2 : 0
+    s2 --> s1 : 0
+    s2 --> s2 : 1
+    s0 --> [*]
+`,
+		},
+		{
+			name:    "trace of 110",
+			options: DiagramOptions{Trace: fsm.Tracer},
+			want: `stateDiagram-v2
+    direction LR
+    state "S0" as s0
+    state "S1" as s1
+    state "S2" as s2
+    [*] --> s0
+    s0 --> s0 : 0 (steps 2)
+    s0 --> s1 : 1 (steps 0)
+    s1 --> s0 : 1 (steps 1)
+    s1 --> s2 : 0
+    s2 --> s1 : 0
+    s2 --> s2 : 1
+    s0 --> [*]
+    classDef visited fill:#ffd54f,stroke:#e65100,stroke-width:2px
+    class s0,s1 visited
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := threeModFA.WriteMermaid(&b, tt.options); err != nil {
+				t.Fatalf("WriteMermaid() error = %v", err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("WriteMermaid() = \n%s\n want \n%s", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestFiniteAutomaton_WritePlantUML(t *testing.T) {
+
+	// accepts binary strings ending in 1, with merged transitions and names that need escaping
+	endsInOneFA, _ := NewFiniteAutomaton(
+		NewSet("start", `got "1"`),
+		NewSet("0", "1", ":"), "start", NewSet(`got "1"`),
+		map[string]map[string]string{
+			"start":   {"0": "start", ":": "start", "1": `got "1"`},
+			`got "1"`: {"0": "start", ":": "start", "1": `got "1"`},
+		})
+	trace := &Trace{Steps: []TraceStep{{Position: 0, Symbol: "1", From: "start", To: `got "1"`}}}
+
+	var b strings.Builder
+	if err := endsInOneFA.WritePlantUML(&b, DiagramOptions{Trace: trace}); err != nil {
+		t.Fatalf("WritePlantUML() error = %v", err)
+	}
+	want := `@startuml
+hide empty description
+left to right direction
+state "got ~"1~"" as s0 #FFD54F
+state "start" as s1 #FFD54F
+[*] --> s1
+s0 --> s0 : 1
+s0 --> s1 : 0, :
+s1 -[#E65100,bold]-> s0 : 1
+s1 --> s1 : 0, :
+s0 --> [*]
+@enduml
+`
+	if b.String() != want {
+		t.Errorf("WritePlantUML() = \n%s\n want \n%s", b.String(), want)
+	}
+
+	// same FA twice gives the same diagram
+	var again strings.Builder
+	_ = endsInOneFA.WritePlantUML(&again, DiagramOptions{Trace: trace})
+	if again.String() != b.String() {
+		t.Errorf("WritePlantUML() is not deterministic")
+	}
+}
+
+func TestFiniteAutomaton_DiagramBadTrace(t *testing.T) {
+
+	fa, _ := NewFiniteAutomaton(NewSet("S0"), NewSet("a"), "S0", NewSet("S0"), map[string]map[string]string{"S0": {"a": "S0"}})
+	trace := &Trace{Steps: []TraceStep{{Symbol: "b", From: "S0", To: "S0"}}}
+
+	var b strings.Builder
+	if err := fa.WriteMermaid(&b, DiagramOptions{Trace: trace}); err == nil || !strings.Contains(err.Error(), "trace step 0") {
+		t.Errorf("WriteMermaid() error = %v, want trace step 0 error", err)
+	}
+	if err := fa.WritePlantUML(&b, DiagramOptions{Trace: trace}); err == nil {
+		t.Errorf("WritePlantUML() expected error")
+	}
+}
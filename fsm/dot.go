@@ -0,0 +1,365 @@
+package fsm
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// dotMarkerShapes are the node shapes of the invisible node whose edge points at the initial state
+var dotMarkerShapes = NewSet("point", "none", "plaintext", "plain")
+
+// dotQuote returns s as a quoted DOT ID
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// WriteDOT writes the FA as a Graphviz digraph: final states are double circles, an arrow from a point node goes into q0,
+// and the inputs of parallel transitions are merged into one edge label like "0, 1". Output is sorted, so it is diff friendly.
+//
+//	Render it with: dot -Tsvg fa.dot > fa.svg
+func (f *FiniteAutomaton) WriteDOT(w io.Writer) error {
+	start := "__start"
+	for f.Q.Contains(start) {
+		start += "_"
+	}
+	var b strings.Builder
+	b.WriteString("digraph FA {\n\trankdir=LR;\n\tnode [shape=circle];\n")
+	fmt.Fprintf(&b, "\t%s [shape=point];\n", dotQuote(start))
+	states := sortedStrings(f.Q)
+	for _, state := range states {
+		if f.F.Contains(state) {
+			fmt.Fprintf(&b, "\t%s [shape=doublecircle];\n", dotQuote(state))
+		} else {
+			fmt.Fprintf(&b, "\t%s;\n", dotQuote(state))
+		}
+	}
+	fmt.Fprintf(&b, "\t%s -> %s;\n", dotQuote(start), dotQuote(f.q0))
+	for _, e := range f.diagramEdges() {
+		fmt.Fprintf(&b, "\t%s -> %s [label=%s];\n", dotQuote(e.from), dotQuote(e.to), dotQuote(e.label))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotToken is a token of a DOT file
+type dotToken struct {
+	text   string
+	quoted bool // quoted is true for "..." IDs, which are never keywords or punctuation
+	line   int
+}
+
+// lexDOT splits a DOT file into tokens, dropping comments
+func lexDOT(src string) ([]dotToken, error) {
+	var tokens []dotToken
+	line := 1
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case r == '\n':
+			line++
+			i++
+		case unicode.IsSpace(r):
+			i += size
+		case strings.HasPrefix(src[i:], "//") || r == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case strings.HasPrefix(src[i:], "->") || strings.HasPrefix(src[i:], "--"):
+			tokens = append(tokens, dotToken{text: src[i : i+2], line: line})
+			i += 2
+		case strings.ContainsRune("{}[];,=", r):
+			tokens = append(tokens, dotToken{text: string(r), line: line})
+			i++
+		case r == '"':
+			var b strings.Builder
+			startLine := line
+			for i++; ; i++ {
+				if i >= len(src) {
+					return nil, fmt.Errorf("line %d: unterminated string", startLine)
+				}
+				if src[i] == '"' {
+					i++
+					break
+				}
+				if src[i] == '\\' && i+1 < len(src) && (src[i+1] == '"' || src[i+1] == '\\') {
+					i++
+				} else if src[i] == '\n' {
+					line++
+				}
+				b.WriteByte(src[i])
+			}
+			tokens = append(tokens, dotToken{text: b.String(), quoted: true, line: startLine})
+		case r == '_' || r == '.' || r >= utf8.RuneSelf || unicode.IsLetter(r) || unicode.IsDigit(r):
+			start := i
+			for i < len(src) {
+				r, size = utf8.DecodeRuneInString(src[i:])
+				if !(r == '_' || r == '.' || r >= utf8.RuneSelf && !unicode.IsSpace(r) || unicode.IsLetter(r) || unicode.IsDigit(r)) {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, dotToken{text: src[start:i], line: line})
+		default:
+			return nil, fmt.Errorf("line %d: unsupported character %q", line, r)
+		}
+	}
+	return tokens, nil
+}
+
+// dotEdge is an edge statement of a DOT file
+type dotEdge struct {
+	from, to string
+	label    *string // label is nil when the edge has no label
+	line     int
+}
+
+// dotParser reads the tokens of a DOT file
+type dotParser struct {
+	tokens []dotToken
+	pos    int
+	shapes map[string]string // shapes maps every node to its shape
+	edges  []dotEdge
+}
+
+// peek returns the current token, or an empty token at the end of the file
+func (p *dotParser) peek() dotToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	line := 1
+	if len(p.tokens) > 0 {
+		line = p.tokens[len(p.tokens)-1].line
+	}
+	return dotToken{line: line}
+}
+
+// is reports whether the current token is the unquoted text, ignoring case
+func (p *dotParser) is(text string) bool {
+	t := p.peek()
+	return !t.quoted && p.pos < len(p.tokens) && strings.EqualFold(t.text, text)
+}
+
+// expect consumes the unquoted text or returns an error
+func (p *dotParser) expect(text string) error {
+	if !p.is(text) {
+		return fmt.Errorf("line %d: expected %s, got %q", p.peek().line, text, p.peek().text)
+	}
+	p.pos++
+	return nil
+}
+
+// id consumes an ID
+func (p *dotParser) id() (dotToken, error) {
+	t := p.peek()
+	if p.pos >= len(p.tokens) || !t.quoted && strings.ContainsAny(t.text, "{}[];,=") || !t.quoted && (t.text == "->" || t.text == "--") {
+		return t, fmt.Errorf("line %d: expected an ID, got %q", t.line, t.text)
+	}
+	p.pos++
+	return t, nil
+}
+
+// attributes consumes any number of [name=value, ...] lists
+func (p *dotParser) attributes() (map[string]string, error) {
+	attributes := make(map[string]string)
+	for p.is("[") {
+		p.pos++
+		for !p.is("]") {
+			name, err := p.id()
+			if err != nil {
+				return nil, err
+			}
+			value := "true"
+			if p.is("=") {
+				p.pos++
+				v, err := p.id()
+				if err != nil {
+					return nil, err
+				}
+				value = v.text
+			}
+			attributes[strings.ToLower(name.text)] = value
+			if p.is(",") || p.is(";") {
+				p.pos++
+			}
+		}
+		p.pos++
+	}
+	return attributes, nil
+}
+
+// parse reads the whole digraph
+func (p *dotParser) parse() error {
+	if p.is("strict") {
+		p.pos++
+	}
+	if p.is("graph") {
+		return fmt.Errorf("line %d: undirected graphs are not supported, use digraph", p.peek().line)
+	}
+	if err := p.expect("digraph"); err != nil {
+		return err
+	}
+	if !p.is("{") {
+		if _, err := p.id(); err != nil {
+			return err
+		}
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	defaultShape := "ellipse"
+	node := func(name string) {
+		if _, exists := p.shapes[name]; !exists {
+			p.shapes[name] = defaultShape
+		}
+	}
+	for !p.is("}") {
+		t := p.peek()
+		switch {
+		case p.pos >= len(p.tokens):
+			return fmt.Errorf("line %d: missing closing }", t.line)
+		case p.is(";") || p.is(","):
+			p.pos++
+		case p.is("subgraph") || p.is("{"):
+			return fmt.Errorf("line %d: subgraphs are not supported", t.line)
+		case p.is("graph") || p.is("node") || p.is("edge"):
+			p.pos++
+			attributes, err := p.attributes()
+			if err != nil {
+				return err
+			}
+			if shape, exists := attributes["shape"]; exists && strings.EqualFold(t.text, "node") {
+				defaultShape = shape
+			}
+		default:
+			first, err := p.id()
+			if err != nil {
+				return err
+			}
+			if p.is("=") { // graph attribute
+				p.pos++
+				if _, err := p.id(); err != nil {
+					return err
+				}
+				continue
+			}
+			if p.is("--") {
+				return fmt.Errorf("line %d: undirected edges are not supported, use ->", t.line)
+			}
+			chain := []string{first.text}
+			for p.is("->") {
+				p.pos++
+				next, err := p.id()
+				if err != nil {
+					return err
+				}
+				chain = append(chain, next.text)
+			}
+			for _, name := range chain {
+				node(name)
+			}
+			attributes, err := p.attributes()
+			if err != nil {
+				return err
+			}
+			if len(chain) == 1 {
+				if shape, exists := attributes["shape"]; exists {
+					p.shapes[first.text] = shape
+				}
+				continue
+			}
+			var label *string
+			if l, exists := attributes["label"]; exists {
+				label = &l
+			}
+			for i := 0; i+1 < len(chain); i++ {
+				p.edges = append(p.edges, dotEdge{from: chain[i], to: chain[i+1], label: label, line: t.line})
+			}
+		}
+	}
+	return nil
+}
+
+// ReadDOT reads an FA from a Graphviz digraph in the form written by WriteDOT, or a hand written one using the same conventions:
+//
+//	states with shape=doublecircle are final, a node with shape=point (or none, plaintext, plain) has one edge into q0, and the
+//	label of every other edge is a comma separated list of inputs. Node defaults like `node [shape=doublecircle]; S1 S2;` are
+//	understood. Subgraphs, ports, HTML labels and undirected graphs are not supported.
+//	The result goes through the same validation as NewFiniteAutomaton
+func ReadDOT(r io.Reader) (*FiniteAutomaton, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := lexDOT(string(src))
+	if err != nil {
+		return nil, err
+	}
+	p := dotParser{tokens: tokens, shapes: make(map[string]string)}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+
+	Q, Sigma, F := NewSet[string](), NewSet[string](), NewSet[string]()
+	for name, shape := range p.shapes {
+		if dotMarkerShapes.Contains(strings.ToLower(shape)) {
+			continue
+		}
+		Q.Add(name)
+		if strings.EqualFold(shape, "doublecircle") {
+			F.Add(name)
+		}
+	}
+	q0 := ""
+	delta := make(map[string]map[string]string)
+	for _, e := range p.edges {
+		if !Q.Contains(e.to) {
+			return nil, fmt.Errorf("line %d: edge %s -> %s goes into the %s node %s", e.line, e.from, e.to, p.shapes[e.to], e.to)
+		}
+		if !Q.Contains(e.from) { // edge from the start marker
+			if q0 != "" && q0 != e.to {
+				return nil, fmt.Errorf("line %d: second initial state %s, q0 is already %s", e.line, e.to, q0)
+			}
+			q0 = e.to
+			continue
+		}
+		if e.label == nil {
+			return nil, fmt.Errorf("line %d: edge %s -> %s has no label", e.line, e.from, e.to)
+		}
+		inputs, err := splitElements(*e.label)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", e.line, err)
+		}
+		if len(inputs) == 0 {
+			return nil, fmt.Errorf("line %d: edge %s -> %s has an empty label", e.line, e.from, e.to)
+		}
+		if delta[e.from] == nil {
+			delta[e.from] = make(map[string]string)
+		}
+		for _, element := range inputs {
+			input, err := unquoteElement(element)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", e.line, err)
+			}
+			if previous, exists := delta[e.from][input]; exists && previous != e.to {
+				return nil, fmt.Errorf("line %d: state %s goes to both %s and %s on input %s", e.line, e.from, previous, e.to, input)
+			}
+			delta[e.from][input] = e.to
+			Sigma.Add(input)
+		}
+	}
+	if q0 == "" {
+		return nil, fmt.Errorf("no initial state, add an edge from a node with shape=point into q0")
+	}
+	return NewFiniteAutomaton(Q, Sigma, q0, F, delta)
+}
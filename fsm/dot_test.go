@@ -0,0 +1,139 @@
+package fsm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFiniteAutomaton_WriteDOT(t *testing.T) {
+
+	// accepts binary strings ending in 1
+	endsInOneFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1"),
+		NewSet("0", "1"), "S0", NewSet("S1"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S0", "1": "S1"},
+		})
+
+	var b strings.Builder
+	if err := endsInOneFA.WriteDOT(&b); err != nil {
+		t.Fatalf("WriteDOT() error = %v", err)
+	}
+	want := `digraph FA {
+	rankdir=LR;
+	node [shape=circle];
+	"__start" [shape=point];
+	"S0";
+	"S1" [shape=doublecircle];
+	"__start" -> "S0";
+	"S0" -> "S0" [label="0"];
+	"S0" -> "S1" [label="1"];
+	"S1" -> "S0" [label="0"];
+	"S1" -> "S1" [label="1"];
+}
+`
+	if b.String() != want {
+		t.Errorf("WriteDOT() = \n%s\n want \n%s", b.String(), want)
+	}
+
+	got, err := ReadDOT(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("ReadDOT() error = %v", err)
+	}
+	if !got.Equals(endsInOneFA) {
+		t.Errorf("ReadDOT() = %v, want %v", got, endsInOneFA)
+	}
+}
+
+func TestReadDOT_RoundTrip(t *testing.T) {
+
+	// merged labels, quoted elements and a state named like the start node
+	trickyFA, _ := NewFiniteAutomaton(
+		NewSet("__start", `say "hi"`),
+		NewSet("a", "b", ",", " "), "__start", NewSet(`say "hi"`),
+		map[string]map[string]string{
+			"__start":  {"a": `say "hi"`, "b": `say "hi"`, ",": "__start", " ": "__start"},
+			`say "hi"`: {"a": `say "hi"`, "b": "__start", ",": `say "hi"`, " ": `say "hi"`},
+		})
+
+	var b strings.Builder
+	if err := trickyFA.WriteDOT(&b); err != nil {
+		t.Fatalf("WriteDOT() error = %v", err)
+	}
+	if !strings.Contains(b.String(), `"__start" -> "say \"hi\"" [label="a, b"];`) {
+		t.Errorf("WriteDOT() should merge parallel transitions, got \n%s", b.String())
+	}
+	got, err := ReadDOT(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("ReadDOT() error = %v, input \n%s", err, b.String())
+	}
+	if !got.Equals(trickyFA) {
+		t.Errorf("ReadDOT() = %v, want %v", got, trickyFA)
+	}
+}
+
+func TestReadDOT_HandWritten(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+
+	src := `/* remainder of a binary number divided by 3 */
+strict digraph threeMod {
+	rankdir = LR
+	size = "8,5"
+	node [shape = doublecircle]; S0 S1 S2;
+	node [shape = point]; start
+	start -> S0
+	S0 -> S0 [label = "0"]
+	S0 -> S1 [label = 1] // unquoted label
+	S1 -> S2 [label = "0"]; S1 -> S0 [label = "1"]
+	# chained edges share the label
+	S2 -> S2 -> S2 [label = 1]
+	S2 -> S1 [label = "0"]
+}`
+	got, err := ReadDOT(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("ReadDOT() error = %v", err)
+	}
+	if !got.Equals(threeModFA) {
+		t.Errorf("ReadDOT() = %v, want %v", got, threeModFA)
+	}
+}
+
+func TestReadDOT_Errors(t *testing.T) {
+
+	header := "digraph {\n__start [shape=point]; __start -> S0; S0 [shape=doublecircle];\n"
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{name: "undirected graph", src: "graph { a -- b }", wantErr: "undirected graphs"},
+		{name: "undirected edge", src: "digraph { a -- b }", wantErr: "undirected edges"},
+		{name: "subgraph", src: "digraph { subgraph cluster { a } }", wantErr: "line 1: subgraphs"},
+		{name: "unterminated string", src: "digraph {\n\"a }", wantErr: "line 2: unterminated string"},
+		{name: "port", src: "digraph {\n a:n -> b }", wantErr: "line 2: unsupported character"},
+		{name: "missing brace", src: header + "S0 -> S0 [label=a]", wantErr: "missing closing }"},
+		{name: "no label", src: header + "S0 -> S0\n}", wantErr: "line 3: edge S0 -> S0 has no label"},
+		{name: "nondeterministic", src: header + "S1 -> S1 [label=a]\nS0 -> S0 [label=a]\nS0 -> S1 [label=\"a, b\"]\n}", wantErr: "line 5: state S0 goes to both S0 and S1 on input a"},
+		{name: "no initial state", src: "digraph { S0 -> S0 [label=a] }", wantErr: "no initial state"},
+		{name: "two initial states", src: header + "__start -> S1\n}", wantErr: "line 3: second initial state S1"},
+		{name: "edge into marker", src: header + "S0 -> __start\n}", wantErr: "line 3: edge S0 -> __start goes into the point node"},
+		{name: "incomplete delta", src: header + "S0 -> S0 [label=a]\nS0 -> S1 [label=b]\nS1 -> S1 [label=a]\n}", wantErr: "delta doesn't contain all"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadDOT(strings.NewReader(tt.src))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadDOT() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,10 +3,14 @@ package fsm
 import (
 	"fmt"
 	"reflect"
+	"strings"
 )
 
 // FiniteAutomaton represents a Finite Automaton.
 //
+//	A FiniteAutomaton is never modified after NewFiniteAutomaton returns, so one FA can be shared by any number of
+//	FiniteStateMachines running in different goroutines. Don't modify Q, Sigma, F or Delta directly after construction.
+//
 //	It is possible to parametrize FiniteAutomaton with T(for states) and U(for input elements), but I think this could be an overkill
 type FiniteAutomaton struct {
 	Q     Set[string]                  // Q is the set of acceptable FSM states.
@@ -62,6 +66,9 @@ func NewFiniteAutomaton(Q Set[string], Sigma Set[string], q0 string, F Set[strin
 		outerKeySet.Add(k)                            //  populate innerkeyset for error checking later
 		innerKeySet := NewSet[string]()               // innerKeySet will hold all the inner map keys, to check that it has to match Sigma
 		for k1, v1 := range v {                       // iterate over inner map
+			if !fa.Q.Contains(v1) {
+				return nil, fmt.Errorf("delta of state %s on input %s goes to %s, which is not one of the acceptable states", k, k1, v1)
+			}
 			fa.Delta[k][k1] = v1
 			innerKeySet.Add(k1) // populate innerkeyset for error checking later
 		}
@@ -82,9 +89,9 @@ func NewFiniteAutomaton(Q Set[string], Sigma Set[string], q0 string, F Set[strin
 	return &fa, nil
 }
 
-// String returns a string representing the FiniteAutomaton as a string
+// String returns a string representing the FiniteAutomaton as a string. ParseFiniteAutomaton reads it back
 func (f *FiniteAutomaton) String() string {
-	return fmt.Sprintf("FA:\n\tQ=%s\n\tΣ=%s\n\tq0=%v\n\tF=%s\n\tδ=%v\n", f.Q.String(), f.Sigma.String(), f.q0, f.F.String(), f.Delta)
+	return fmt.Sprintf("FA:\n\tQ=%s\n\tΣ=%s\n\tq0=%v\n\tF=%s\n\tδ=%s\n", f.Q.String(), f.Sigma.String(), quoteElement(f.q0), f.F.String(), f.deltaString())
 }
 
 // NewFiniteStateMachine returns a new FiniteStateMachine with initialized state
@@ -112,3 +119,61 @@ func (f *FiniteAutomaton) Equals(otherFSM *FiniteAutomaton) bool {
 	}
 	return true
 }
+
+// tupleState names a state built from several component states, like product or composition states, as "(a,b,...)".
+//
+//	Components are quoted like Set elements when they contain ",", "(", ")", a quote or whitespace, or are empty, so distinct
+//	tuples always get distinct names: ("a,b","c") is "(\"a,b\",c)" and ("a","b,c") is "(a,\"b,c\")"
+func tupleState(states ...string) string {
+	quoted := make([]string, len(states))
+	for i, state := range states {
+		quoted[i] = quoteElement(state)
+	}
+	return "(" + strings.Join(quoted, ",") + ")"
+}
+
+// DeadStates returns the states reachable from q0 from which no state of F can be reached anymore, sorted.
+// A run that enters one of them is stuck for good, which is how deadlocks show up in a product of machines
+func (f *FiniteAutomaton) DeadStates() []string {
+	symbols := sortedStrings(f.Sigma)
+
+	// states reachable from q0
+	reachable := NewSet(f.q0)
+	queue := []string{f.q0}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for _, symbol := range symbols {
+			if next := f.Delta[state][symbol]; !reachable.Contains(next) {
+				reachable.Add(next)
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	// states that can reach F, walking Delta backwards
+	live := f.F.DeepCopy()
+	for changed := true; changed; {
+		changed = false
+		for state, bySymbol := range f.Delta {
+			if live.Contains(state) {
+				continue
+			}
+			for _, next := range bySymbol {
+				if live.Contains(next) {
+					live.Add(state)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	var dead []string
+	for _, state := range sortedStrings(reachable) {
+		if !live.Contains(state) {
+			dead = append(dead, state)
+		}
+	}
+	return dead
+}
@@ -2,6 +2,7 @@ package fsm
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -123,6 +124,20 @@ func TestNewFiniteAutomaton(t *testing.T) {
 			want:        nil,
 			expectedErr: fmt.Errorf("delta doesn't contain all the possible input possibilities"),
 		},
+		{
+			name:  "Delta goes to a state not in Q",
+			Q:     NewSet("S0", "S1", "S2"),
+			Sigma: NewSet("0", "1"),
+			q0:    "S0",
+			F:     NewSet("S0", "S1", "S2"),
+			Delta: map[string]map[string]string{
+				"S0": {"0": "S0", "1": "S1"},
+				"S1": {"0": "S2", "1": "S0"},
+				"S2": {"0": "S1", "1": "S3"},
+			},
+			want:        nil,
+			expectedErr: fmt.Errorf("delta of state S2 on input 1 goes to S3, which is not one of the acceptable states"),
+		},
 		{
 			name:  "threeModFA green test creation",
 			Q:     NewSet("S0", "S1", "S2"),
@@ -178,7 +193,7 @@ func TestFiniteAutomaton_String(t *testing.T) {
 	Σ=(0, 1)
 	q0=S0
 	F=(S0, S1, S2)
-	δ=map[S0:map[0:S0 1:S1] S1:map[0:S2 1:S0] S2:map[0:S1 1:S2]]
+	δ=((S0, 0, S0), (S0, 1, S1), (S1, 0, S2), (S1, 1, S0), (S2, 0, S1), (S2, 1, S2))
 `,
 		},
 	}
@@ -228,3 +243,63 @@ func TestFiniteAutomaton_NewFiniteStateMachine(t *testing.T) {
 		})
 	}
 }
+
+func TestFiniteAutomaton_DeadStates(t *testing.T) {
+
+	// S2 is a trap, S3 is unreachable
+	trapFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2", "S3"),
+		NewSet("0", "1"), "S0", NewSet("S1"),
+		map[string]map[string]string{
+			"S0": {"0": "S1", "1": "S2"},
+			"S1": {"0": "S0", "1": "S1"},
+			"S2": {"0": "S2", "1": "S2"},
+			"S3": {"0": "S3", "1": "S3"},
+		})
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+
+	tests := []struct {
+		name string
+		fa   *FiniteAutomaton
+		want []string
+	}{
+		{name: "trap state", fa: trapFA, want: []string{"S2"}},
+		{name: "no dead state", fa: threeModFA, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fa.DeadStates(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FiniteAutomaton.DeadStates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTupleState(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		states []string
+		want   string
+	}{
+		{name: "plain", states: []string{"S0", "T1"}, want: "(S0,T1)"},
+		{name: "comma in first", states: []string{"a,b", "c"}, want: `("a,b",c)`},
+		{name: "comma in second", states: []string{"a", "b,c"}, want: `(a,"b,c")`},
+		{name: "parenthesis", states: []string{"(a)", "b"}, want: `("(a)",b)`},
+		{name: "empty", states: []string{"a", ""}, want: `(a,"")`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tupleState(tt.states...); got != tt.want {
+				t.Errorf("tupleState() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
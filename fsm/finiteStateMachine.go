@@ -12,7 +12,12 @@ import (
 type FiniteStateMachine struct {
 	FA              *FiniteAutomaton // FA represents the FiniteAutomaton that configures this FSM. It is equivalent to FSM config object
 	currentState    string
-	OutputConverter func(string) (int, error) // OutputConverter, converts a state to an output. This is a flexible way to convert states to outputs
+	position        int                                       // position is the number of runes processed so far
+	OutputConverter func(string) (int, error)                 // OutputConverter, converts a state to an output. This is a flexible way to convert states to outputs
+	Tracer          *Trace                                    // Tracer, if not nil, records every step taken by the FSM. Useful to debug rejected inputs
+	hooks           *hookRegistry                             // hooks holds the OnEnter/OnExit/OnTransition callbacks. nil until the first hook is registered
+	guards          map[string]map[string][]GuardedTransition // guards holds the guarded transitions of an ExtendedFiniteAutomaton, if any
+	Data            any                                       // Data is the user supplied extended state that guards are evaluated against
 }
 
 // NewFiniteAutomaton creates a new FSM with the tuple (Q,Σ,q0,F,δ). Does initial error checking as well.
@@ -37,16 +42,54 @@ func (f *FiniteStateMachine) String() string {
 	return fmt.Sprintf("FSM: \n\tFA=%s\n", f.FA.String())
 }
 
+// CurrentState returns the state the FSM is currently in
+func (f *FiniteStateMachine) CurrentState() string {
+	return f.currentState
+}
+
 // GetNextState gets the next state based on next input rune and currentState. This func corresponds to a func equivalent of delta instead of a double map.
 //
 //	Potentially we can make this extensible by allowing user to provide this func
 func (f *FiniteStateMachine) ProcessInputRune(inputRune string) error {
+	nextState, err := f.prepareTransition(inputRune)
+	if err != nil {
+		return err
+	}
+	f.takeTransition(inputRune, nextState)
+	return nil
+}
+
+// prepareTransition checks inputRune, resolves the next state and runs the hooks, without moving the FSM.
+// Returns the next state, or an error if the rune is rejected or a hook vetoed the transition
+func (f *FiniteStateMachine) prepareTransition(inputRune string) (string, error) {
 	// check if the rune is acceptable
 	if !f.FA.Sigma.Contains(string(inputRune)) {
-		return fmt.Errorf("rune %v is not an acceptable input", inputRune)
+		if f.Tracer != nil {
+			path := f.Tracer.Path()
+			if path == "" {
+				path = f.currentState // rejected before the first step
+			}
+			return "", fmt.Errorf("rune %v at position %d is not an acceptable input. path=%s", inputRune, f.position, path)
+		}
+		return "", fmt.Errorf("rune %v is not an acceptable input", inputRune)
 	}
-	f.currentState = f.FA.Delta[f.currentState][inputRune]
-	return nil
+	nextState, err := f.nextState(inputRune)
+	if err != nil {
+		return "", err
+	}
+	if err := f.runHooks(inputRune, f.currentState, nextState); err != nil {
+		return "", err // a hook vetoed the transition, stay in current state
+	}
+	return nextState, nil
+}
+
+// takeTransition records the transition prepared by prepareTransition and moves the FSM to nextState
+func (f *FiniteStateMachine) takeTransition(inputRune string, nextState string) {
+	if f.Tracer != nil {
+		f.Tracer.Record(TraceStep{Position: f.position, Symbol: inputRune, From: f.currentState, To: nextState})
+	}
+	f.currentState = nextState
+	f.position++
 }
 
 // GetFSMOutput gets the output of the FSM based on the given inputs. returns an error if it encounters an error in processing
@@ -59,13 +102,30 @@ func (f *FiniteStateMachine) GetFSMOutput(input string) (int, error) {
 		}
 	}
 
+	return f.output()
+}
+
+// output checks that the current state is one of the accepted final states and converts it to an output
+func (f *FiniteStateMachine) output() (int, error) {
+	finalState, err := f.acceptedState()
+	if err != nil {
+		return 0, err
+	}
+	// convert the finalState to an output string using the output converter func
+	return f.OutputConverter(finalState)
+}
+
+// acceptedState returns the current state if it is one of the accepted final states, or an error otherwise
+func (f *FiniteStateMachine) acceptedState() (string, error) {
 	// finalState is here when done with processing input
 	finalState := f.currentState
 
 	// check if final state is one of the accepted states
 	if !f.FA.F.Contains(finalState) {
-		return 0, fmt.Errorf("state %s is not one of the accepted final states. F=%v", finalState, f.FA.F)
+		if f.Tracer != nil && f.Tracer.Len() > 0 {
+			return "", fmt.Errorf("state %s is not one of the accepted final states. F=%v. path=%s", finalState, f.FA.F, f.Tracer.Path())
+		}
+		return "", fmt.Errorf("state %s is not one of the accepted final states. F=%v", finalState, f.FA.F)
 	}
-	// convert the finalState to an output string using the output converter func
-	return f.OutputConverter(finalState)
+	return finalState, nil
 }
@@ -0,0 +1,130 @@
+package fsm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Guard is a predicate evaluated against the user supplied extended state of a FiniteStateMachine (FiniteStateMachine.Data).
+//
+//	A transition whose guard returns false is not enabled
+type Guard func(data any) bool
+
+// GuardedTransition is a transition that is only taken when its Guard holds
+type GuardedTransition struct {
+	To       string // To is the state to go to when the transition is taken
+	Guard    Guard  // Guard is the condition for the transition. A nil Guard is always enabled, which makes it an "else" branch
+	Priority int    // Priority orders the transitions of the same state and symbol. Higher priority is evaluated first, ties keep declaration order
+}
+
+// ExtendedFiniteAutomaton is a FiniteAutomaton where some (state, symbol) pairs are resolved by guarded transitions (EFSM).
+//
+//	Guarded transitions of a (state, symbol) pair replace the Delta entry of that pair. Pairs without guards use Delta as usual
+type ExtendedFiniteAutomaton struct {
+	*FiniteAutomaton
+	Guards map[string]map[string][]GuardedTransition // Guards is a map between state, input and the guarded transitions, sorted by priority
+}
+
+// GuardGap is a (state, symbol) pair whose guards could all be false, leaving no enabled transition
+type GuardGap struct {
+	State  string
+	Symbol string
+}
+
+// NewExtendedFiniteAutomaton creates an ExtendedFiniteAutomaton from fa and a map of guarded transitions. Does initial error checking as well.
+func NewExtendedFiniteAutomaton(fa *FiniteAutomaton, guards map[string]map[string][]GuardedTransition) (*ExtendedFiniteAutomaton, error) {
+	if fa == nil {
+		return nil, fmt.Errorf("FA is nil")
+	}
+	efa := ExtendedFiniteAutomaton{FiniteAutomaton: fa, Guards: make(map[string]map[string][]GuardedTransition, len(guards))}
+
+	// deep copy guards and error check in the process
+	for state, bySymbol := range guards {
+		if !fa.Q.Contains(state) {
+			return nil, fmt.Errorf("guarded state %s is not one of the acceptable states", state)
+		}
+		efa.Guards[state] = make(map[string][]GuardedTransition, len(bySymbol))
+		for symbol, transitions := range bySymbol {
+			if !fa.Sigma.Contains(symbol) {
+				return nil, fmt.Errorf("guarded input %s of state %s is not an acceptable input", symbol, state)
+			}
+			if len(transitions) == 0 {
+				return nil, fmt.Errorf("state %s has an empty list of guarded transitions on input %s", state, symbol)
+			}
+			for _, t := range transitions {
+				if !fa.Q.Contains(t.To) {
+					return nil, fmt.Errorf("guarded transition %s -%s-> %s goes to a state that is not one of the acceptable states", state, symbol, t.To)
+				}
+			}
+			sorted := append([]GuardedTransition(nil), transitions...)
+			sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Priority > sorted[j].Priority })
+			efa.Guards[state][symbol] = sorted
+		}
+	}
+
+	return &efa, nil
+}
+
+// GuardGaps returns the (state, symbol) pairs that have no unconditional (nil Guard) transition, sorted by state then symbol.
+//
+//	Guards are arbitrary funcs, so any such pair can end up with no enabled transition at runtime
+func (e *ExtendedFiniteAutomaton) GuardGaps() []GuardGap {
+	var gaps []GuardGap
+	for state, bySymbol := range e.Guards {
+		for symbol, transitions := range bySymbol {
+			hasElse := false
+			for _, t := range transitions {
+				if t.Guard == nil {
+					hasElse = true
+					break
+				}
+			}
+			if !hasElse {
+				gaps = append(gaps, GuardGap{State: state, Symbol: symbol})
+			}
+		}
+	}
+	sort.Slice(gaps, func(i, j int) bool {
+		if gaps[i].State != gaps[j].State {
+			return gaps[i].State < gaps[j].State
+		}
+		return gaps[i].Symbol < gaps[j].Symbol
+	})
+	return gaps
+}
+
+// Validate returns an error listing the guard sets that can leave a state/symbol pair with no enabled transition. See GuardGaps
+func (e *ExtendedFiniteAutomaton) Validate() error {
+	gaps := e.GuardGaps()
+	if len(gaps) == 0 {
+		return nil
+	}
+	pairs := make([]string, len(gaps))
+	for i, g := range gaps {
+		pairs[i] = fmt.Sprintf("(%s, %s)", g.State, g.Symbol)
+	}
+	return fmt.Errorf("guards can leave no enabled transition for %s", strings.Join(pairs, ", "))
+}
+
+// NewFiniteStateMachine returns a new FiniteStateMachine with initialized state, evaluating guards against data
+func (e *ExtendedFiniteAutomaton) NewFiniteStateMachine(data any) *FiniteStateMachine {
+	f := e.FiniteAutomaton.NewFiniteStateMachine()
+	f.guards = e.Guards
+	f.Data = data
+	return f
+}
+
+// nextState resolves the state to go to from the current state on inputRune, evaluating guards if the pair has any
+func (f *FiniteStateMachine) nextState(inputRune string) (string, error) {
+	transitions, guarded := f.guards[f.currentState][inputRune]
+	if !guarded {
+		return f.FA.Delta[f.currentState][inputRune], nil
+	}
+	for _, t := range transitions {
+		if t.Guard == nil || t.Guard(f.Data) {
+			return t.To, nil
+		}
+	}
+	return "", fmt.Errorf("no enabled transition from state %s on rune %v", f.currentState, inputRune)
+}
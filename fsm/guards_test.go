@@ -0,0 +1,129 @@
+package fsm
+
+import (
+	"reflect"
+	"testing"
+)
+
+// retryData is the extended state used by the guard tests
+type retryData struct {
+	retries int
+}
+
+func TestExtendedFiniteAutomaton_Retries(t *testing.T) {
+
+	// S0 is idle, S1 is failing, S2 is giving up. "1" is a failure, "0" is a success
+	fa, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S0", "1": "S2"},
+			"S2": {"0": "S2", "1": "S2"},
+		})
+	efa, err := NewExtendedFiniteAutomaton(fa, map[string]map[string][]GuardedTransition{
+		"S1": {"1": {
+			{To: "S1"}, // else branch, lowest priority
+			{To: "S2", Priority: 1, Guard: func(data any) bool { return data.(*retryData).retries >= 3 }},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("NewExtendedFiniteAutomaton() error = %v", err)
+	}
+	if err := efa.Validate(); err != nil {
+		t.Errorf("ExtendedFiniteAutomaton.Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{name: "success resets", input: "110", want: 0},
+		{name: "under retry limit", input: "111", want: 1},
+		{name: "over retry limit", input: "11111", want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &retryData{}
+			fsm := efa.NewFiniteStateMachine(data)
+			fsm.OnTransition("S1", "1", func(symbol string, from string, to string) error {
+				data.retries++
+				return nil
+			})
+			got, err := fsm.GetFSMOutput(tt.input)
+			if err != nil {
+				t.Fatalf("FSM.GetFSMOutput() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FSM.GetFSMOutput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtendedFiniteAutomaton_GuardGaps(t *testing.T) {
+
+	fa, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S0", "1": "S1"},
+		})
+	never := func(data any) bool { return false }
+
+	efa, err := NewExtendedFiniteAutomaton(fa, map[string]map[string][]GuardedTransition{
+		"S0": {"1": {{To: "S1", Guard: never}}},
+		"S1": {"0": {{To: "S0", Guard: never}, {To: "S1"}}},
+	})
+	if err != nil {
+		t.Fatalf("NewExtendedFiniteAutomaton() error = %v", err)
+	}
+	want := []GuardGap{{State: "S0", Symbol: "1"}}
+	if got := efa.GuardGaps(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtendedFiniteAutomaton.GuardGaps() = %v, want %v", got, want)
+	}
+	if err := efa.Validate(); err == nil {
+		t.Errorf("ExtendedFiniteAutomaton.Validate() expected error")
+	}
+
+	// the gap shows up at runtime as an error and the FSM stays put
+	fsm := efa.NewFiniteStateMachine(nil)
+	if err := fsm.ProcessInputRune("1"); err == nil {
+		t.Errorf("ProcessInputRune() expected no enabled transition error")
+	}
+	if fsm.CurrentState() != "S0" {
+		t.Errorf("CurrentState() = %v, want S0", fsm.CurrentState())
+	}
+}
+
+func TestNewExtendedFiniteAutomaton_Errors(t *testing.T) {
+
+	fa, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S0", "1": "S1"},
+		})
+
+	tests := []struct {
+		name   string
+		fa     *FiniteAutomaton
+		guards map[string]map[string][]GuardedTransition
+	}{
+		{name: "nil FA", fa: nil},
+		{name: "unknown state", fa: fa, guards: map[string]map[string][]GuardedTransition{"S9": {"0": {{To: "S0"}}}}},
+		{name: "unknown symbol", fa: fa, guards: map[string]map[string][]GuardedTransition{"S0": {"2": {{To: "S0"}}}}},
+		{name: "unknown target", fa: fa, guards: map[string]map[string][]GuardedTransition{"S0": {"0": {{To: "S9"}}}}},
+		{name: "empty transitions", fa: fa, guards: map[string]map[string][]GuardedTransition{"S0": {"0": {}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewExtendedFiniteAutomaton(tt.fa, tt.guards); err == nil {
+				t.Errorf("NewExtendedFiniteAutomaton() expected error")
+			}
+		})
+	}
+}
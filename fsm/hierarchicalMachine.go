@@ -0,0 +1,319 @@
+package fsm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// History selects what a composite state remembers when it is left and entered again
+type History int
+
+const (
+	NoHistory      History = iota // NoHistory always enters the composite state through its initial substate
+	ShallowHistory                // ShallowHistory enters the direct substate that was active last, which then follows its own rules
+	DeepHistory                   // DeepHistory enters the leaf state that was active last
+)
+
+// HierarchicalState declares a state of a HierarchicalMachine. A state with substates is a composite state
+type HierarchicalState struct {
+	Name    string  // Name is the state name, unique across the whole machine
+	Parent  string  // Parent is the composite state containing this state, or "" for a top level state
+	Initial string  // Initial is the substate entered by default. Required for composite states, empty for leaf states
+	History History // History selects how a composite state is entered again
+}
+
+// HierarchicalMachine represents a hierarchical state machine (statechart) with nested states.
+//
+//	Only leaf states are ever active. An input is looked up on the active leaf, then on its parents, so composite states
+//	share their transitions with all their substates. Entering a composite state goes to its initial substate or its history.
+//	An input without transition anywhere up the hierarchy is ignored and leaves the machine where it is
+type HierarchicalMachine struct {
+	Sigma       Set[string]                  // Sigma is the acceptable set of inputs
+	States      map[string]HierarchicalState // States is the list of states, by name
+	Transitions map[string]map[string]string // Transitions is a map between state (leaf or composite), input and target state (leaf or composite)
+	Initial     string                       // Initial is the state entered when the machine starts
+	F           Set[string]                  // F is the set of final states. A leaf is final if it or one of its parents is in F
+
+	children map[string][]string // children lists the direct substates of composite states, sorted
+	history  []string            // history lists the composite states with history, sorted
+}
+
+// NewHierarchicalMachine creates a new HierarchicalMachine. Does initial error checking as well.
+func NewHierarchicalMachine(Sigma Set[string], states []HierarchicalState, transitions map[string]map[string]string, initial string, F Set[string]) (*HierarchicalMachine, error) {
+	if len(Sigma) == 0 {
+		return nil, fmt.Errorf("Σ(Sigma)=(list of acceptable input) is empty")
+	}
+	if len(states) == 0 {
+		return nil, fmt.Errorf("list of states is empty")
+	}
+	hm := HierarchicalMachine{
+		Sigma:       Sigma.DeepCopy(),
+		States:      make(map[string]HierarchicalState, len(states)),
+		Transitions: make(map[string]map[string]string, len(transitions)),
+		Initial:     initial,
+		F:           F.DeepCopy(),
+		children:    make(map[string][]string),
+	}
+	for _, s := range states {
+		if s.Name == "" {
+			return nil, fmt.Errorf("state name can't be empty")
+		}
+		if _, exists := hm.States[s.Name]; exists {
+			return nil, fmt.Errorf("state %s is declared twice", s.Name)
+		}
+		hm.States[s.Name] = s
+	}
+
+	// check the tree: parents exist, no cycles, composite states have an initial direct substate
+	for _, s := range states {
+		if s.Parent != "" {
+			if _, exists := hm.States[s.Parent]; !exists {
+				return nil, fmt.Errorf("parent %s of state %s is not one of the states", s.Parent, s.Name)
+			}
+			hm.children[s.Parent] = append(hm.children[s.Parent], s.Name)
+		}
+		depth := 0
+		for p := s.Parent; p != ""; p = hm.States[p].Parent {
+			if depth++; depth > len(states) {
+				return nil, fmt.Errorf("state %s is its own ancestor", s.Name)
+			}
+		}
+	}
+	for _, s := range states {
+		sort.Strings(hm.children[s.Name])
+		if len(hm.children[s.Name]) == 0 {
+			if s.Initial != "" {
+				return nil, fmt.Errorf("leaf state %s can't have an initial substate", s.Name)
+			}
+			if s.History != NoHistory {
+				return nil, fmt.Errorf("leaf state %s can't have history", s.Name)
+			}
+			continue
+		}
+		if child, exists := hm.States[s.Initial]; !exists || child.Parent != s.Name {
+			return nil, fmt.Errorf("initial substate %q of composite state %s is not one of its substates", s.Initial, s.Name)
+		}
+		if s.History != NoHistory {
+			hm.history = append(hm.history, s.Name)
+		}
+	}
+	sort.Strings(hm.history)
+
+	if _, exists := hm.States[initial]; !exists {
+		return nil, fmt.Errorf("initial state %s is not one of the states", initial)
+	}
+	if len(F) == 0 {
+		return nil, fmt.Errorf("F(list of acceptable final states) is empty")
+	}
+	for state := range F {
+		if _, exists := hm.States[state]; !exists {
+			return nil, fmt.Errorf("final state %s is not one of the states", state)
+		}
+	}
+
+	// deep copy transitions and error check in the process
+	for from, bySymbol := range transitions {
+		if _, exists := hm.States[from]; !exists {
+			return nil, fmt.Errorf("transition source %s is not one of the states", from)
+		}
+		hm.Transitions[from] = make(map[string]string, len(bySymbol))
+		for symbol, to := range bySymbol {
+			if !hm.Sigma.Contains(symbol) {
+				return nil, fmt.Errorf("transition input %s of state %s is not an acceptable input", symbol, from)
+			}
+			if _, exists := hm.States[to]; !exists {
+				return nil, fmt.Errorf("transition target %s of state %s is not one of the states", to, from)
+			}
+			hm.Transitions[from][symbol] = to
+		}
+	}
+	return &hm, nil
+}
+
+// String returns a string representing the HierarchicalMachine as a string: the leaf states, then the substates of every composite state
+func (h *HierarchicalMachine) String() string {
+	leaves := NewSet[string]()
+	for state := range h.States {
+		if len(h.children[state]) == 0 {
+			leaves.Add(state)
+		}
+	}
+	return fmt.Sprintf("HSM:\n\tΣ=%s\n\tstates=%s\n\tcomposites=%v\n\tinitial=%v\n\tF=%s\n\tδ=%v\n", h.Sigma.String(), leaves.String(), h.children, h.Initial, h.F.String(), h.Transitions)
+}
+
+// hierarchicalConfig is the runtime configuration of a HierarchicalMachine: the active leaf and the history of composite states
+type hierarchicalConfig struct {
+	leaf    string
+	history map[string]string // history maps a composite state with history to its last active direct substate (shallow) or leaf (deep)
+}
+
+// enter returns the leaf state reached by entering state, following initial substates and history
+func (h *HierarchicalMachine) enter(state string, history map[string]string) string {
+	for len(h.children[state]) > 0 {
+		s := h.States[state]
+		if remembered, exists := history[state]; exists && s.History == DeepHistory {
+			return remembered
+		} else if exists && s.History == ShallowHistory {
+			state = remembered
+		} else {
+			state = s.Initial
+		}
+	}
+	return state
+}
+
+// start returns the initial configuration
+func (h *HierarchicalMachine) start() hierarchicalConfig {
+	history := make(map[string]string)
+	return hierarchicalConfig{leaf: h.enter(h.Initial, history), history: history}
+}
+
+// step processes one input from config and returns the next configuration
+func (h *HierarchicalMachine) step(config hierarchicalConfig, inputRune string) (hierarchicalConfig, error) {
+	if !h.Sigma.Contains(inputRune) {
+		return config, fmt.Errorf("rune %v is not an acceptable input", inputRune)
+	}
+	// look the input up on the active leaf, then on its parents
+	target, found := "", false
+	for s := config.leaf; s != "" && !found; s = h.States[s].Parent {
+		target, found = h.Transitions[s][inputRune]
+	}
+	if !found {
+		return config, nil // unhandled input, stay put
+	}
+
+	// record the history of the composite states the active leaf is in, before leaving it
+	history := make(map[string]string, len(config.history))
+	for k, v := range config.history {
+		history[k] = v
+	}
+	for child := config.leaf; h.States[child].Parent != ""; child = h.States[child].Parent {
+		parent := h.States[child].Parent
+		switch h.States[parent].History {
+		case ShallowHistory:
+			history[parent] = child
+		case DeepHistory:
+			history[parent] = config.leaf
+		}
+	}
+	return hierarchicalConfig{leaf: h.enter(target, history), history: history}, nil
+}
+
+// isFinal reports whether leaf or one of its parents is in F
+func (h *HierarchicalMachine) isFinal(leaf string) bool {
+	for s := leaf; s != ""; s = h.States[s].Parent {
+		if h.F.Contains(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// flatName names a configuration in the flattened automaton: the leaf alone, or the leaf and the history of every history
+// composite state as "(leaf,h1,h2,...)"
+func (h *HierarchicalMachine) flatName(config hierarchicalConfig) string {
+	if len(h.history) == 0 {
+		return config.leaf
+	}
+	parts := []string{config.leaf}
+	for _, state := range h.history {
+		parts = append(parts, config.history[state])
+	}
+	return tupleState(parts...)
+}
+
+// Flatten returns the plain FiniteAutomaton equivalent to the machine, for analysis. Its states are the reachable configurations:
+// leaf states when no composite state has history, "(leaf,history...)" tuples otherwise. Ignored inputs become self loops
+func (h *HierarchicalMachine) Flatten() (*FiniteAutomaton, error) {
+	symbols := sortedStrings(h.Sigma)
+	start := h.start()
+	Q := NewSet(h.flatName(start))
+	F := NewSet[string]()
+	Delta := make(map[string]map[string]string)
+	queue := []hierarchicalConfig{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		name := h.flatName(current)
+		if h.isFinal(current.leaf) {
+			F.Add(name)
+		}
+		Delta[name] = make(map[string]string, len(symbols))
+		for _, symbol := range symbols {
+			next, _ := h.step(current, symbol) // symbol is in Sigma, can't fail
+			nextName := h.flatName(next)
+			if !Q.Contains(nextName) {
+				Q.Add(nextName)
+				queue = append(queue, next)
+			}
+			Delta[name][symbol] = nextName
+		}
+	}
+	if F.Size() == 0 {
+		return nil, fmt.Errorf("no final state is reachable")
+	}
+	return NewFiniteAutomaton(Q, h.Sigma, h.flatName(start), F, Delta)
+}
+
+// HierarchicalStateMachine runs a HierarchicalMachine natively, keeping track of the active leaf and history
+type HierarchicalStateMachine struct {
+	HM     *HierarchicalMachine // HM represents the HierarchicalMachine that configures this machine
+	config hierarchicalConfig
+}
+
+// NewHierarchicalStateMachine returns a new HierarchicalStateMachine with initialized state
+func (h *HierarchicalMachine) NewHierarchicalStateMachine() *HierarchicalStateMachine {
+	return &HierarchicalStateMachine{HM: h, config: h.start()}
+}
+
+// String returns a string representing the HierarchicalStateMachine as a string
+func (m *HierarchicalStateMachine) String() string {
+	return fmt.Sprintf("HSM: \n\tactive=%v\n\tHM=%s\n", m.ActiveStates(), m.HM.String())
+}
+
+// CurrentState returns the active leaf state
+func (m *HierarchicalStateMachine) CurrentState() string {
+	return m.config.leaf
+}
+
+// ActiveStates returns the active leaf state followed by all its parents, innermost first
+func (m *HierarchicalStateMachine) ActiveStates() []string {
+	var active []string
+	for s := m.config.leaf; s != ""; s = m.HM.States[s].Parent {
+		active = append(active, s)
+	}
+	return active
+}
+
+// IsIn reports whether state is active, either as the active leaf or as one of its parents
+func (m *HierarchicalStateMachine) IsIn(state string) bool {
+	for s := m.config.leaf; s != ""; s = m.HM.States[s].Parent {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// ProcessInputRune processes a single input rune. See FiniteStateMachine.ProcessInputRune
+func (m *HierarchicalStateMachine) ProcessInputRune(inputRune string) error {
+	next, err := m.HM.step(m.config, inputRune)
+	if err != nil {
+		return err
+	}
+	m.config = next
+	return nil
+}
+
+// Run processes input and returns the active leaf state. returns an error if the leaf state is not final, or on a bad input
+func (m *HierarchicalStateMachine) Run(input string) (string, error) {
+	for _, r := range input {
+		if err := m.ProcessInputRune(string(r)); err != nil {
+			return "", err
+		}
+	}
+	if !m.HM.isFinal(m.config.leaf) {
+		return "", fmt.Errorf("state %s is not one of the accepted final states. F=%v", m.config.leaf, m.HM.F)
+	}
+	return m.config.leaf, nil
+}
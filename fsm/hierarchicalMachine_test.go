@@ -0,0 +1,161 @@
+package fsm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newWorkflowMachine returns a workflow where "working" is a composite of "fetch" and "process" (itself a composite of
+// "parse" and "store"). Inputs: s(tart), n(ext), p(ause), r(esume), c(ancel)
+func newWorkflowMachine(t *testing.T, history History) *HierarchicalMachine {
+	hm, err := NewHierarchicalMachine(
+		NewSet("s", "n", "p", "r", "c"),
+		[]HierarchicalState{
+			{Name: "idle"},
+			{Name: "working", Initial: "fetch", History: history},
+			{Name: "fetch", Parent: "working"},
+			{Name: "process", Parent: "working", Initial: "parse"},
+			{Name: "parse", Parent: "process"},
+			{Name: "store", Parent: "process"},
+			{Name: "paused"},
+		},
+		map[string]map[string]string{
+			"idle":    {"s": "working"},
+			"fetch":   {"n": "process"},
+			"parse":   {"n": "store"},
+			"store":   {"n": "idle"},
+			"working": {"p": "paused", "c": "idle"}, // inherited by every substate
+			"paused":  {"r": "working", "c": "idle"},
+		},
+		"idle", NewSet("idle", "paused"))
+	if err != nil {
+		t.Fatalf("NewHierarchicalMachine() error = %v", err)
+	}
+	return hm
+}
+
+func TestHierarchicalStateMachine_Run(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		history History
+		input   string
+		want    string // active leaf after input, without the final state check
+	}{
+		{name: "initial substates", history: NoHistory, input: "s", want: "fetch"},
+		{name: "nested initial substate", history: NoHistory, input: "sn", want: "parse"},
+		{name: "inherited transition", history: NoHistory, input: "snnp", want: "paused"},
+		{name: "no history resume", history: NoHistory, input: "snnpr", want: "fetch"},
+		{name: "shallow history resume", history: ShallowHistory, input: "snnpr", want: "parse"},
+		{name: "deep history resume", history: DeepHistory, input: "snnpr", want: "store"},
+		{name: "ignored input", history: NoHistory, input: "snr", want: "parse"},
+		{name: "history survives cancel", history: DeepHistory, input: "snncs", want: "store"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newWorkflowMachine(t, tt.history).NewHierarchicalStateMachine()
+			for _, r := range tt.input {
+				if err := m.ProcessInputRune(string(r)); err != nil {
+					t.Fatalf("ProcessInputRune() error = %v", err)
+				}
+			}
+			if got := m.CurrentState(); got != tt.want {
+				t.Errorf("CurrentState() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	m := newWorkflowMachine(t, NoHistory).NewHierarchicalStateMachine()
+	if _, err := m.Run("sn"); err == nil {
+		t.Errorf("Run() expected error for a non final leaf")
+	}
+	if got := m.ActiveStates(); !reflect.DeepEqual(got, []string{"parse", "process", "working"}) {
+		t.Errorf("ActiveStates() = %v", got)
+	}
+	if !m.IsIn("working") || m.IsIn("idle") {
+		t.Errorf("IsIn() doesn't match active states %v", m.ActiveStates())
+	}
+	if got, err := m.Run("p"); err != nil || got != "paused" {
+		t.Errorf("Run() = %v, %v, want paused", got, err)
+	}
+}
+
+func TestHierarchicalMachine_Flatten(t *testing.T) {
+
+	for _, history := range []History{NoHistory, ShallowHistory, DeepHistory} {
+		hm := newWorkflowMachine(t, history)
+		fa, err := hm.Flatten()
+		if err != nil {
+			t.Fatalf("Flatten() error = %v", err)
+		}
+		if history == NoHistory && !fa.Q.IsSubset(NewSet("idle", "fetch", "parse", "store", "paused")) {
+			t.Errorf("Flatten() without history Q = %v, want leaf states", fa.Q)
+		}
+
+		// the flat FA and the native runtime agree on every input up to length 5
+		inputs := []string{""}
+		for length := 0; length < 5; length++ {
+			var longer []string
+			for _, input := range inputs {
+				for _, symbol := range []string{"s", "n", "p", "r", "c"} {
+					longer = append(longer, input+symbol)
+				}
+			}
+			inputs = append(inputs, longer...)
+			for _, input := range longer {
+				native := hm.NewHierarchicalStateMachine()
+				_, nativeErr := native.Run(input)
+				flat := fa.NewFiniteStateMachine()
+				flat.OutputConverter = func(string) (int, error) { return 0, nil }
+				_, flatErr := flat.GetFSMOutput(input)
+				if (nativeErr == nil) != (flatErr == nil) {
+					t.Fatalf("history %v input %s: native error = %v, flat error = %v", history, input, nativeErr, flatErr)
+				}
+				if history == NoHistory && flat.CurrentState() != native.CurrentState() {
+					t.Fatalf("input %s: native state = %v, flat state = %v", input, native.CurrentState(), flat.CurrentState())
+				}
+				if history != NoHistory && !strings.HasPrefix(flat.CurrentState(), "("+native.CurrentState()+",") {
+					t.Fatalf("input %s: native state = %v, flat state = %v", input, native.CurrentState(), flat.CurrentState())
+				}
+			}
+			inputs = longer
+		}
+	}
+}
+
+func TestHierarchicalMachine_String(t *testing.T) {
+
+	got := newWorkflowMachine(t, NoHistory).String()
+	if !strings.Contains(got, "states=(fetch, idle, parse, paused, store)") || !strings.Contains(got, "composites=map[process:[parse store] working:[fetch process]]") {
+		t.Errorf("HierarchicalMachine.String() = %v", got)
+	}
+
+	// a machine with only leaf states still lists them
+	flat, _ := NewHierarchicalMachine(NewSet("a"), []HierarchicalState{{Name: "on"}, {Name: "off"}},
+		map[string]map[string]string{"on": {"a": "off"}, "off": {"a": "on"}}, "off", NewSet("on"))
+	if got := flat.String(); !strings.Contains(got, "states=(off, on)") {
+		t.Errorf("HierarchicalMachine.String() = %v", got)
+	}
+}
+
+func TestNewHierarchicalMachine(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		states []HierarchicalState
+	}{
+		{name: "duplicate state", states: []HierarchicalState{{Name: "a"}, {Name: "a"}}},
+		{name: "unknown parent", states: []HierarchicalState{{Name: "a", Parent: "b"}}},
+		{name: "cycle", states: []HierarchicalState{{Name: "a", Parent: "b", Initial: "b"}, {Name: "b", Parent: "a", Initial: "a"}}},
+		{name: "composite without initial", states: []HierarchicalState{{Name: "a"}, {Name: "b", Parent: "a"}}},
+		{name: "leaf with history", states: []HierarchicalState{{Name: "a", History: DeepHistory}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewHierarchicalMachine(NewSet("x"), tt.states, nil, "a", NewSet("a")); err == nil {
+				t.Errorf("NewHierarchicalMachine() expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,533 @@
+package fsm
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// HOA (Hanoi Omega-Automata) files describe automata for model checkers like Spot. Sigma is encoded with one atomic proposition
+// (AP) per input: the input at index i of the AP list is the valuation where only proposition i is true. F is the Büchi
+// acceptance set 0, on states:
+//
+//	HOA: v1
+//	States: 2
+//	Start: 0
+//	AP: 2 "0" "1"
+//	acc-name: Buchi
+//	Acceptance: 1 Inf(0)
+//	properties: explicit-labels state-acc deterministic
+//	--BODY--
+//	State: 0 "S0"
+//	[0&!1] 0
+//	[!0&1] 1
+//	State: 1 "S1" {0}
+//	[0&!1] 0
+//	[!0&1] 1
+//	--END--
+
+// hoaQuote returns s as a HOA string
+func hoaQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// hoaCube returns the label of the valuation where only proposition index is true, among n propositions, like "!0&1&!2"
+func hoaCube(index, n int) string {
+	literals := make([]string, n)
+	for i := range literals {
+		literals[i] = strconv.Itoa(i)
+		if i != index {
+			literals[i] = "!" + literals[i]
+		}
+	}
+	return strings.Join(literals, "&")
+}
+
+// WriteHOA writes the FA in the HOA format, with explicit labels on every edge. Edges going to the same state are merged, with
+// a label like "[0&!1 | !0&1]". States are numbered in sorted order and keep their name
+func (f *FiniteAutomaton) WriteHOA(w io.Writer) error {
+	states := sortedStrings(f.Q)
+	index := make(map[string]int, len(states))
+	for i, state := range states {
+		index[state] = i
+	}
+	inputs := sortedStrings(f.Sigma)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "HOA: v1\nStates: %d\nStart: %d\nAP: %d", len(states), index[f.q0], len(inputs))
+	for _, input := range inputs {
+		b.WriteString(" " + hoaQuote(input))
+	}
+	b.WriteString("\nacc-name: Buchi\nAcceptance: 1 Inf(0)\nproperties: explicit-labels state-acc deterministic\n--BODY--\n")
+	for _, state := range states {
+		fmt.Fprintf(&b, "State: %d %s", index[state], hoaQuote(state))
+		if f.F.Contains(state) {
+			b.WriteString(" {0}")
+		}
+		b.WriteString("\n")
+		cubes := make([][]string, len(states)) // cubes of the edge to every state, by index
+		for i, input := range inputs {
+			to := index[f.Delta[state][input]]
+			cubes[to] = append(cubes[to], hoaCube(i, len(inputs)))
+		}
+		for to, labels := range cubes {
+			if len(labels) > 0 {
+				fmt.Fprintf(&b, "[%s] %d\n", strings.Join(labels, " | "), to)
+			}
+		}
+	}
+	b.WriteString("--END--\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// hoaToken is a token of a HOA file
+type hoaToken struct {
+	kind string // kind is "header" (like "States:", without the colon), "identifier", "string", "int", "alias", "body", "end", "abort", the punctuation itself, or "" at the end of the file
+	text string
+	line int
+}
+
+// lexHOA splits a HOA file into tokens, dropping comments
+func lexHOA(src string) ([]hoaToken, error) {
+	var tokens []hoaToken
+	line := 1
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case unicode.IsSpace(c):
+			i++
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+end], "\n")
+			i += end + 2
+		case strings.HasPrefix(src[i:], "--BODY--"), strings.HasPrefix(src[i:], "--END--"), strings.HasPrefix(src[i:], "--ABORT--"):
+			end := strings.Index(src[i+2:], "--") + 4
+			tokens = append(tokens, hoaToken{kind: strings.ToLower(src[i+2 : i+end-2]), text: src[i : i+end], line: line})
+			i += end
+		case c == '"':
+			var b strings.Builder
+			start := line
+			for i++; ; i++ {
+				if i >= len(src) {
+					return nil, fmt.Errorf("line %d: unterminated string", start)
+				}
+				if src[i] == '"' {
+					i++
+					break
+				}
+				if src[i] == '\\' && i+1 < len(src) {
+					i++
+				}
+				if src[i] == '\n' {
+					line++
+				}
+				b.WriteByte(src[i])
+			}
+			tokens = append(tokens, hoaToken{kind: "string", text: b.String(), line: start})
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && src[i] >= '0' && src[i] <= '9' {
+				i++
+			}
+			tokens = append(tokens, hoaToken{kind: "int", text: src[start:i], line: line})
+		case c == '@' || c == '_' || unicode.IsLetter(c):
+			start := i
+			for i++; i < len(src) && (src[i] == '_' || src[i] == '-' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))); i++ {
+			}
+			kind := "identifier"
+			if c == '@' {
+				kind = "alias"
+			} else if i < len(src) && src[i] == ':' {
+				kind = "header"
+				i++
+			}
+			tokens = append(tokens, hoaToken{kind: kind, text: src[start:i], line: line})
+			if kind == "header" {
+				tokens[len(tokens)-1].text = src[start : i-1]
+			}
+		case strings.ContainsRune("[]{}()!&|", c):
+			tokens = append(tokens, hoaToken{kind: string(c), text: string(c), line: line})
+			i++
+		default:
+			return nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+		}
+	}
+	return tokens, nil
+}
+
+// hoaParser reads the tokens of a HOA file
+type hoaParser struct {
+	tokens []hoaToken
+	pos    int
+	ap     []string // ap are the atomic propositions, the inputs
+}
+
+// peek returns the current token, or a token of kind "" at the end of the file
+func (p *hoaParser) peek() hoaToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	line := 1
+	if len(p.tokens) > 0 {
+		line = p.tokens[len(p.tokens)-1].line
+	}
+	return hoaToken{line: line}
+}
+
+// expect consumes a token of kind or returns an error
+func (p *hoaParser) expect(kind string) (hoaToken, error) {
+	t := p.peek()
+	if t.kind != kind {
+		return t, fmt.Errorf("line %d: expected %s, got %q", t.line, kind, t.text)
+	}
+	p.pos++
+	return t, nil
+}
+
+// integer consumes an int token
+func (p *hoaParser) integer() (int, hoaToken, error) {
+	t, err := p.expect("int")
+	if err != nil {
+		return 0, t, err
+	}
+	n, err := strconv.Atoi(t.text)
+	return n, t, err
+}
+
+// hoaLabel is an edge label, evaluated on the valuation where only the proposition at the given index is true
+type hoaLabel func(index int) bool
+
+// label parses a label expression: disjunctions of conjunctions of literals, with t, f, ! and parentheses
+func (p *hoaParser) label() (hoaLabel, error) {
+	left, err := p.conjunction()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == "|" {
+		p.pos++
+		right, err := p.conjunction()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(index int) bool { return l(index) || right(index) }
+	}
+	return left, nil
+}
+
+// conjunction parses literals joined with &
+func (p *hoaParser) conjunction() (hoaLabel, error) {
+	left, err := p.literal()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == "&" {
+		p.pos++
+		right, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(index int) bool { return l(index) && right(index) }
+	}
+	return left, nil
+}
+
+// literal parses t, f, a proposition, a negation or a parenthesized label
+func (p *hoaParser) literal() (hoaLabel, error) {
+	t := p.peek()
+	p.pos++
+	switch {
+	case t.kind == "!":
+		operand, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		return func(index int) bool { return !operand(index) }, nil
+	case t.kind == "(":
+		inner, err := p.label()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	case t.kind == "identifier" && t.text == "t":
+		return func(int) bool { return true }, nil
+	case t.kind == "identifier" && t.text == "f":
+		return func(int) bool { return false }, nil
+	case t.kind == "int":
+		proposition, _ := strconv.Atoi(t.text)
+		if proposition >= len(p.ap) {
+			return nil, fmt.Errorf("line %d: proposition %d is not in AP, which has %d propositions", t.line, proposition, len(p.ap))
+		}
+		return func(index int) bool { return index == proposition }, nil
+	case t.kind == "alias":
+		return nil, fmt.Errorf("line %d: alias %s is not supported", t.line, t.text)
+	}
+	return nil, fmt.Errorf("line %d: unexpected %q in label", t.line, t.text)
+}
+
+// hoaEdge is an edge of a HOA state
+type hoaEdge struct {
+	label hoaLabel
+	to    int
+	line  int
+}
+
+// hoaState is a state of the body of a HOA file
+type hoaState struct {
+	name      string
+	accepting Set[int]
+	edges     []hoaEdge
+	line      int
+}
+
+// ReadHOA reads an FA from a HOA file with explicit edge labels, in the encoding written by WriteHOA: the atomic propositions are
+// Sigma, and an input is the valuation where only its proposition is true.
+//
+//	Valuations where several propositions, or none, are true are not inputs of the FA: edges are only evaluated on the inputs,
+//	and edges that match no input, like [!0&!1] in a complete automaton from Spot, are ignored. So are overlaps outside the
+//	inputs, like [0] and [1] on 0&1: determinism is only checked for the inputs.
+//
+//	Acceptance must be "1 Inf(0)" on states (F is acceptance set 0), or "0 t" (every state is final). Every state needs exactly one
+//	target for every input. Nondeterminism, several initial states, universal branching, implicit or state labels, aliases and
+//	transition-based acceptance have no FiniteAutomaton equivalent and are errors.
+//
+//	Sigma is always taken from the AP: header. Use ReadHOAWithSigma to check it against an expected alphabet
+func ReadHOA(r io.Reader) (*FiniteAutomaton, error) {
+	return ReadHOAWithSigma(r, nil)
+}
+
+// ReadHOAWithSigma is ReadHOA for an expected alphabet: propositions of AP: that are not in Sigma, and inputs of Sigma that AP:
+// doesn't list, are errors. A nil Sigma is taken from the file, like ReadHOA does
+func ReadHOAWithSigma(r io.Reader, Sigma Set[string]) (*FiniteAutomaton, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := lexHOA(string(src))
+	if err != nil {
+		return nil, err
+	}
+	p := hoaParser{tokens: tokens}
+
+	// header
+	if t := p.peek(); t.kind != "header" || t.text != "HOA" {
+		return nil, fmt.Errorf("line %d: a HOA file starts with HOA: v1", t.line)
+	}
+	declared, start, acceptance := -1, -1, ""
+	for p.peek().kind == "header" {
+		header := p.peek()
+		p.pos++
+		switch header.text {
+		case "HOA":
+			if version, err := p.expect("identifier"); err != nil || !strings.HasPrefix(version.text, "v1") {
+				return nil, fmt.Errorf("line %d: only HOA v1 is supported", header.line)
+			}
+		case "States":
+			if declared, _, err = p.integer(); err != nil {
+				return nil, err
+			}
+		case "Start":
+			n, t, err := p.integer()
+			if err != nil {
+				return nil, err
+			}
+			if start >= 0 {
+				return nil, fmt.Errorf("line %d: second initial state %d, a FiniteAutomaton has one initial state", t.line, n)
+			}
+			if p.peek().kind == "&" {
+				return nil, fmt.Errorf("line %d: universal initial states are not supported", t.line)
+			}
+			start = n
+		case "AP":
+			n, _, err := p.integer()
+			if err != nil {
+				return nil, err
+			}
+			seen := NewSet[string]()
+			for i := 0; i < n; i++ {
+				t, err := p.expect("string")
+				if err != nil {
+					return nil, err
+				}
+				if seen.Contains(t.text) {
+					return nil, fmt.Errorf("line %d: proposition %s is listed twice in AP", t.line, t.text)
+				}
+				if Sigma != nil && !Sigma.Contains(t.text) {
+					return nil, fmt.Errorf("line %d: proposition %s is not an input of Sigma", t.line, t.text)
+				}
+				seen.Add(t.text)
+				p.ap = append(p.ap, t.text)
+			}
+		case "Alias":
+			return nil, fmt.Errorf("line %d: aliases are not supported", header.line)
+		case "Acceptance":
+			var parts []string
+			for k := p.peek().kind; k != "header" && k != "body" && k != ""; k = p.peek().kind {
+				parts = append(parts, p.peek().text)
+				p.pos++
+			}
+			if len(parts) > 0 {
+				acceptance = parts[0] + " " + strings.Join(parts[1:], "") // like "1 Inf(0)"
+			}
+			if acceptance != "1 Inf(0)" && acceptance != "0 t" {
+				return nil, fmt.Errorf("line %d: acceptance %s is not supported, use 1 Inf(0) with F as set 0", header.line, acceptance)
+			}
+		default:
+			if unicode.IsUpper(rune(header.text[0])) {
+				return nil, fmt.Errorf("line %d: header %s: is not supported", header.line, header.text)
+			}
+			// headers starting with a lower case letter, like name: or properties:, can be ignored
+			for k := p.peek().kind; k != "header" && k != "body" && k != ""; k = p.peek().kind {
+				p.pos++
+			}
+		}
+	}
+	if t := p.peek(); t.kind == "abort" {
+		return nil, fmt.Errorf("line %d: HOA file was aborted", t.line)
+	}
+	if _, err := p.expect("body"); err != nil {
+		return nil, err
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("no Start: header, a FiniteAutomaton needs an initial state")
+	}
+	if acceptance == "" {
+		return nil, fmt.Errorf("no Acceptance: header")
+	}
+	ap := NewSet(p.ap...)
+	for _, input := range sortedStrings(Sigma) {
+		if !ap.Contains(input) {
+			return nil, fmt.Errorf("input %s of Sigma is not a proposition of AP", input)
+		}
+	}
+
+	// body
+	states := make(map[int]*hoaState)
+	for p.peek().kind == "header" && p.peek().text == "State" {
+		header := p.peek()
+		p.pos++
+		if p.peek().kind == "[" {
+			return nil, fmt.Errorf("line %d: state labels are not supported, label the edges", header.line)
+		}
+		id, t, err := p.integer()
+		if err != nil {
+			return nil, err
+		}
+		if states[id] != nil {
+			return nil, fmt.Errorf("line %d: state %d is defined twice", t.line, id)
+		}
+		s := &hoaState{name: strconv.Itoa(id), accepting: NewSet[int](), line: t.line}
+		states[id] = s
+		if p.peek().kind == "string" {
+			s.name = p.peek().text
+			p.pos++
+		}
+		if p.peek().kind == "{" {
+			p.pos++
+			for p.peek().kind == "int" {
+				set, _, _ := p.integer()
+				s.accepting.Add(set)
+			}
+			if _, err := p.expect("}"); err != nil {
+				return nil, err
+			}
+		}
+		for {
+			t := p.peek()
+			if t.kind == "int" {
+				return nil, fmt.Errorf("line %d: implicit labels are not supported, label the edges", t.line)
+			}
+			if t.kind != "[" {
+				break
+			}
+			p.pos++
+			label, err := p.label()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			to, _, err := p.integer()
+			if err != nil {
+				return nil, err
+			}
+			if p.peek().kind == "&" {
+				return nil, fmt.Errorf("line %d: universal branching is not supported", t.line)
+			}
+			if p.peek().kind == "{" {
+				return nil, fmt.Errorf("line %d: transition-based acceptance is not supported, put acceptance sets on states", t.line)
+			}
+			s.edges = append(s.edges, hoaEdge{label: label, to: to, line: t.line})
+		}
+	}
+	if t := p.peek(); t.kind == "abort" {
+		return nil, fmt.Errorf("line %d: HOA file was aborted", t.line)
+	}
+	if _, err := p.expect("end"); err != nil {
+		return nil, err
+	}
+
+	// build the FA
+	if declared >= 0 && len(states) != declared {
+		return nil, fmt.Errorf("States: declares %d states, the body has %d", declared, len(states))
+	}
+	if states[start] == nil {
+		return nil, fmt.Errorf("initial state %d is not defined", start)
+	}
+	Q, F := NewSet[string](), NewSet[string]()
+	names := make(map[string]int, len(states))
+	for id, s := range states {
+		if other, exists := names[s.name]; exists {
+			return nil, fmt.Errorf("states %d and %d have the same name %s", other, id, s.name)
+		}
+		names[s.name] = id
+		Q.Add(s.name)
+		for set := range s.accepting {
+			if set != 0 || acceptance == "0 t" {
+				return nil, fmt.Errorf("line %d: state %s is in acceptance set %d, which is not in Acceptance", s.line, s.name, set)
+			}
+		}
+		if acceptance == "0 t" || s.accepting.Contains(0) {
+			F.Add(s.name)
+		}
+	}
+	delta := make(map[string]map[string]string, len(states))
+	for _, s := range states {
+		delta[s.name] = make(map[string]string, len(p.ap))
+		for _, e := range s.edges {
+			target := states[e.to]
+			if target == nil {
+				return nil, fmt.Errorf("line %d: edge of state %s goes to undefined state %d", e.line, s.name, e.to)
+			}
+			for i, input := range p.ap {
+				if !e.label(i) {
+					continue
+				}
+				if previous, exists := delta[s.name][input]; exists && previous != target.name {
+					return nil, fmt.Errorf("line %d: state %s goes to both %s and %s on input %s, a FiniteAutomaton is deterministic", e.line, s.name, previous, target.name, input)
+				}
+				delta[s.name][input] = target.name
+			}
+		}
+		for _, input := range p.ap {
+			if _, exists := delta[s.name][input]; !exists {
+				return nil, fmt.Errorf("line %d: state %s has no edge for input %s", s.line, s.name, input)
+			}
+		}
+	}
+	return NewFiniteAutomaton(Q, ap, states[start].name, F, delta)
+}
@@ -0,0 +1,201 @@
+package fsm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// modThreeHOA is the mod three FA, written the way WriteHOA writes it
+const modThreeHOA = `HOA: v1
+States: 3
+Start: 0
+AP: 2 "0" "1"
+acc-name: Buchi
+Acceptance: 1 Inf(0)
+properties: explicit-labels state-acc deterministic
+--BODY--
+State: 0 "S0" {0}
+[0&!1] 0
+[!0&1] 1
+State: 1 "S1"
+[!0&1] 0
+[0&!1] 2
+State: 2 "S2"
+[0&!1] 1
+[!0&1] 2
+--END--
+`
+
+func TestReadHOA(t *testing.T) {
+
+	modThreeFA, _ := NewFiniteAutomaton(NewSet("S0", "S1", "S2"), NewSet("0", "1"), "S0", NewSet("S0"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+	fa, err := ReadHOA(strings.NewReader(modThreeHOA))
+	if err != nil {
+		t.Fatalf("ReadHOA() error = %v", err)
+	}
+	if !fa.Equals(modThreeFA) {
+		t.Errorf("ReadHOA() = %v, want %v", fa, modThreeFA)
+	}
+
+	// a HOA file round trips unchanged
+	var b strings.Builder
+	if err := fa.WriteHOA(&b); err != nil {
+		t.Fatalf("WriteHOA() error = %v", err)
+	}
+	if b.String() != modThreeHOA {
+		t.Errorf("WriteHOA() = \n%s\n want \n%s", b.String(), modThreeHOA)
+	}
+
+	// hand written: unnamed states, comments, short labels, t and disjunctions
+	handWritten := `HOA: v1 /* a switch */
+name: "switch"
+States: 2
+Start: 1
+AP: 3 "on" "off" "tick"
+acc-name: Buchi
+Acceptance: 1 Inf(0)
+properties: trans-labels explicit-labels state-acc
+--BODY--
+State: 0 {0}
+[1] 1
+[0 | 2] 0
+State: 1
+[t] 1
+--END--`
+	fa, err = ReadHOA(strings.NewReader(handWritten))
+	if err != nil {
+		t.Fatalf("ReadHOA() error = %v", err)
+	}
+	switchFA, _ := NewFiniteAutomaton(NewSet("0", "1"), NewSet("on", "off", "tick"), "1", NewSet("0"),
+		map[string]map[string]string{
+			"0": {"on": "0", "off": "1", "tick": "0"},
+			"1": {"on": "1", "off": "1", "tick": "1"},
+		})
+	if !fa.Equals(switchFA) {
+		t.Errorf("ReadHOA() = %v, want %v", fa, switchFA)
+	}
+
+	// a complete automaton has edges for valuations that are not inputs, like no proposition or both true: they are ignored
+	complete := `HOA: v1
+name: "G(a -> Fb)"
+States: 2
+Start: 0
+AP: 2 "a" "b"
+acc-name: Buchi
+Acceptance: 1 Inf(0)
+properties: trans-labels explicit-labels state-acc complete
+properties: deterministic
+--BODY--
+State: 0 {0}
+[!0 | 1] 0
+[0&!1] 1
+State: 1
+[1] 0
+[!0&!1] 1
+[0&!1] 1
+--END--`
+	fa, err = ReadHOA(strings.NewReader(complete))
+	if err != nil {
+		t.Fatalf("ReadHOA() error = %v", err)
+	}
+	responseFA, _ := NewFiniteAutomaton(NewSet("0", "1"), NewSet("a", "b"), "0", NewSet("0"),
+		map[string]map[string]string{
+			"0": {"a": "1", "b": "0"},
+			"1": {"a": "1", "b": "0"},
+		})
+	if !fa.Equals(responseFA) {
+		t.Errorf("ReadHOA() = %v, want %v", fa, responseFA)
+	}
+
+	// names with quotes and backslashes are escaped
+	quotedFA, _ := NewFiniteAutomaton(NewSet(`a"b`), NewSet(`x\y`), `a"b`, NewSet(`a"b`), map[string]map[string]string{`a"b`: {`x\y`: `a"b`}})
+	b.Reset()
+	if err := quotedFA.WriteHOA(&b); err != nil {
+		t.Fatalf("WriteHOA() error = %v", err)
+	}
+	if !strings.Contains(b.String(), `AP: 1 "x\\y"`) || !strings.Contains(b.String(), `State: 0 "a\"b" {0}`) {
+		t.Errorf("WriteHOA() = \n%s", b.String())
+	}
+	fa, err = ReadHOA(strings.NewReader(b.String()))
+	if err != nil || !fa.Equals(quotedFA) {
+		t.Errorf("ReadHOA() of WriteHOA() = %v, %v, want %v", fa, err, quotedFA)
+	}
+}
+
+func TestReadHOAWithSigma(t *testing.T) {
+
+	fa, err := ReadHOAWithSigma(strings.NewReader(modThreeHOA), NewSet("0", "1"))
+	if err != nil || !reflect.DeepEqual(fa.Sigma, NewSet("0", "1")) {
+		t.Errorf("ReadHOAWithSigma() = %v, %v, want Sigma (0, 1)", fa, err)
+	}
+
+	tests := []struct {
+		name    string
+		Sigma   Set[string]
+		wantErr string
+	}{
+		{name: "proposition not in Sigma", Sigma: NewSet("0", "2"), wantErr: "line 4: proposition 1 is not an input of Sigma"},
+		{name: "input not in AP", Sigma: NewSet("0", "1", "2"), wantErr: "input 2 of Sigma is not a proposition of AP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadHOAWithSigma(strings.NewReader(modThreeHOA), tt.Sigma)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadHOAWithSigma() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadHOA_Errors(t *testing.T) {
+
+	// automaton wraps a body with the headers of a one state automaton, with inputs a and b
+	automaton := func(headers, body string) string {
+		return "HOA: v1\nStates: 1\n" + headers + "AP: 2 \"a\" \"b\"\nAcceptance: 1 Inf(0)\n--BODY--\n" + body + "--END--\n"
+	}
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "not hoa", data: "States: 1", wantErr: "starts with HOA: v1"},
+		{name: "version", data: "HOA: v2\n", wantErr: "only HOA v1"},
+		{name: "multiple initial states", data: automaton("Start: 0\nStart: 1\n", ""), wantErr: "line 4: second initial state 1"},
+		{name: "universal initial state", data: automaton("Start: 0&1\n", ""), wantErr: "universal initial states"},
+		{name: "no start", data: automaton("", "State: 0 {0}\n[t] 0\n"), wantErr: "no Start: header"},
+		{name: "duplicate proposition", data: "HOA: v1\nStart: 0\nAP: 2 \"a\" \"a\"\n", wantErr: "proposition a is listed twice"},
+		{name: "alias", data: "HOA: v1\nAlias: @a 0\n", wantErr: "aliases are not supported"},
+		{name: "acceptance", data: "HOA: v1\nStart: 0\nAcceptance: 2 Inf(0)&Fin(1)\n", wantErr: "acceptance 2 Inf(0)&Fin(1) is not supported"},
+		{name: "unknown header", data: "HOA: v1\nStart: 0\nControllable: 0\n", wantErr: "header Controllable: is not supported"},
+		{name: "state label", data: automaton("Start: 0\n", "State: [0] 0\n"), wantErr: "state labels are not supported"},
+		{name: "implicit labels", data: automaton("Start: 0\n", "State: 0 {0}\n0\n0\n0\n0\n"), wantErr: "line 8: implicit labels are not supported"},
+		{name: "universal branching", data: automaton("Start: 0\n", "State: 0 {0}\n[t] 0&0\n"), wantErr: "universal branching"},
+		{name: "transition acceptance", data: automaton("Start: 0\n", "State: 0\n[t] 0 {0}\n"), wantErr: "transition-based acceptance"},
+		{name: "unknown proposition", data: automaton("Start: 0\n", "State: 0 {0}\n[2] 0\n"), wantErr: "line 8: proposition 2 is not in AP"},
+		{name: "label alias", data: automaton("Start: 0\n", "State: 0 {0}\n[@x] 0\n"), wantErr: "alias @x is not supported"},
+		{name: "only edges outside the inputs", data: automaton("Start: 0\n", "State: 0 {0}\n[0&1] 0\n[!0&!1] 0\n"), wantErr: "state 0 has no edge for input a"},
+		{name: "nondeterministic", data: "HOA: v1\nStart: 0\nAP: 1 \"a\"\nAcceptance: 1 Inf(0)\n--BODY--\nState: 0 {0}\n[0] 0\n[t] 1\nState: 1\n[t] 1\n--END--\n", wantErr: "line 8: state 0 goes to both 0 and 1 on input a"},
+		{name: "incomplete", data: automaton("Start: 0\n", "State: 0 {0}\n[0] 0\n"), wantErr: "state 0 has no edge for input b"},
+		{name: "undefined target", data: automaton("Start: 0\n", "State: 0 {0}\n[t] 3\n"), wantErr: "goes to undefined state 3"},
+		{name: "undefined start", data: automaton("Start: 1\n", "State: 0 {0}\n[t] 0\n"), wantErr: "initial state 1 is not defined"},
+		{name: "state count", data: automaton("Start: 0\n", ""), wantErr: "States: declares 1 states, the body has 0"},
+		{name: "duplicate state", data: automaton("Start: 0\n", "State: 0\n[t] 0\nState: 0\n"), wantErr: "state 0 is defined twice"},
+		{name: "acceptance set", data: automaton("Start: 0\n", "State: 0 {1}\n[t] 0\n"), wantErr: "acceptance set 1"},
+		{name: "aborted", data: "HOA: v1\nStart: 0\nAcceptance: 0 t\n--BODY--\n--ABORT--\n", wantErr: "aborted"},
+		{name: "no final state", data: automaton("Start: 0\n", "State: 0\n[t] 0\n"), wantErr: "F(list of acceptable final states) is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadHOA(strings.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadHOA() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
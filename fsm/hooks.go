@@ -0,0 +1,143 @@
+package fsm
+
+import "fmt"
+
+// TransitionHook is a callback fired by a FiniteStateMachine around a transition. It receives the input symbol and both states.
+//
+//	Returning an error vetoes the transition: the FSM stays in its current state and ProcessInputRune returns the error.
+//	Hooks earlier in the chain have already run and are not undone, for example exit hooks when an enter hook vetoes
+type TransitionHook func(symbol string, from string, to string) error
+
+// transitionKey identifies a transition by its source state and input symbol
+type transitionKey struct {
+	from   string
+	symbol string
+}
+
+// hookRegistry holds the hooks registered on a FiniteStateMachine. Wildcard hooks are kept apart from the hooks of a state or
+// symbol, so any string, even "", can be a state
+type hookRegistry struct {
+	onExit          map[string][]TransitionHook        // onExit is keyed by the state being left
+	onExitAny       []TransitionHook                   // onExitAny fires when leaving any state
+	onTransition    map[transitionKey][]TransitionHook // onTransition is keyed by (from state, symbol)
+	onSymbol        map[string][]TransitionHook        // onSymbol is keyed by symbol, from any state
+	onTransitionAny []TransitionHook                   // onTransitionAny fires on every transition
+	onEnter         map[string][]TransitionHook        // onEnter is keyed by the state being entered
+	onEnterAny      []TransitionHook                   // onEnterAny fires when entering any state
+}
+
+func newHookRegistry() *hookRegistry {
+	return &hookRegistry{
+		onExit:       make(map[string][]TransitionHook),
+		onTransition: make(map[transitionKey][]TransitionHook),
+		onSymbol:     make(map[string][]TransitionHook),
+		onEnter:      make(map[string][]TransitionHook),
+	}
+}
+
+// registry returns the hook registry of the FSM, creating it on first use
+func (f *FiniteStateMachine) registry() *hookRegistry {
+	if f.hooks == nil {
+		f.hooks = newHookRegistry()
+	}
+	return f.hooks
+}
+
+// checkHookState returns an error if state is not one of the states of the FA
+func (f *FiniteStateMachine) checkHookState(state string) error {
+	if !f.FA.Q.Contains(state) {
+		return fmt.Errorf("state %s is not one of the acceptable states", state)
+	}
+	return nil
+}
+
+// checkHookSymbol returns an error if symbol is not one of the inputs of the FA
+func (f *FiniteStateMachine) checkHookSymbol(symbol string) error {
+	if !f.FA.Sigma.Contains(symbol) {
+		return fmt.Errorf("rune %v is not an acceptable input", symbol)
+	}
+	return nil
+}
+
+// OnEnter registers a hook that fires before the FSM enters state. Use OnEnterAny to fire on every transition
+func (f *FiniteStateMachine) OnEnter(state string, hook TransitionHook) error {
+	if err := f.checkHookState(state); err != nil {
+		return err
+	}
+	f.registry().onEnter[state] = append(f.registry().onEnter[state], hook)
+	return nil
+}
+
+// OnEnterAny registers a hook that fires before the FSM enters any state
+func (f *FiniteStateMachine) OnEnterAny(hook TransitionHook) {
+	f.registry().onEnterAny = append(f.registry().onEnterAny, hook)
+}
+
+// OnExit registers a hook that fires before the FSM leaves state, on any symbol. Use OnExitAny to fire on every transition
+func (f *FiniteStateMachine) OnExit(state string, hook TransitionHook) error {
+	if err := f.checkHookState(state); err != nil {
+		return err
+	}
+	f.registry().onExit[state] = append(f.registry().onExit[state], hook)
+	return nil
+}
+
+// OnExitAny registers a hook that fires before the FSM leaves any state
+func (f *FiniteStateMachine) OnExitAny(hook TransitionHook) {
+	f.registry().onExitAny = append(f.registry().onExitAny, hook)
+}
+
+// OnTransition registers a hook that fires when the FSM takes the transition from state "from" on input "symbol".
+//
+//	Use OnExit for every symbol of a state, OnSymbol for a symbol from every state, and OnTransitionAny for every transition
+func (f *FiniteStateMachine) OnTransition(from string, symbol string, hook TransitionHook) error {
+	if err := f.checkHookState(from); err != nil {
+		return err
+	}
+	if err := f.checkHookSymbol(symbol); err != nil {
+		return err
+	}
+	key := transitionKey{from: from, symbol: symbol}
+	f.registry().onTransition[key] = append(f.registry().onTransition[key], hook)
+	return nil
+}
+
+// OnSymbol registers a hook that fires when the FSM takes a transition on input symbol, from any state
+func (f *FiniteStateMachine) OnSymbol(symbol string, hook TransitionHook) error {
+	if err := f.checkHookSymbol(symbol); err != nil {
+		return err
+	}
+	f.registry().onSymbol[symbol] = append(f.registry().onSymbol[symbol], hook)
+	return nil
+}
+
+// OnTransitionAny registers a hook that fires on every transition
+func (f *FiniteStateMachine) OnTransitionAny(hook TransitionHook) {
+	f.registry().onTransitionAny = append(f.registry().onTransitionAny, hook)
+}
+
+// runHooks fires the hooks matching a transition, in order: exit hooks, transition hooks, then enter hooks.
+//
+//	Specific hooks fire before wildcard ones. The first error stops the chain and is returned
+func (f *FiniteStateMachine) runHooks(symbol string, from string, to string) error {
+	if f.hooks == nil {
+		return nil
+	}
+	chains := [][]TransitionHook{
+		f.hooks.onExit[from],
+		f.hooks.onExitAny,
+		f.hooks.onTransition[transitionKey{from: from, symbol: symbol}],
+		f.hooks.onSymbol[symbol],
+		f.hooks.onTransitionAny,
+		f.hooks.onEnter[to],
+		f.hooks.onEnterAny,
+	}
+	for _, chain := range chains {
+		for _, hook := range chain {
+			if err := hook(symbol, from, to); err != nil {
+				return fmt.Errorf("transition %s -%s-> %s vetoed: %w", from, symbol, to, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestFiniteStateMachine_Hooks(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+
+	fsm := threeModFA.NewFiniteStateMachine()
+	var calls []string
+	record := func(kind string) TransitionHook {
+		return func(symbol string, from string, to string) error {
+			calls = append(calls, fmt.Sprintf("%s %s-%s->%s", kind, from, symbol, to))
+			return nil
+		}
+	}
+	if err := fsm.OnExit("S0", record("exit")); err != nil {
+		t.Fatalf("OnExit() error = %v", err)
+	}
+	if err := fsm.OnTransition("S1", "0", record("transition")); err != nil {
+		t.Fatalf("OnTransition() error = %v", err)
+	}
+	if err := fsm.OnEnter("S2", record("enter")); err != nil {
+		t.Fatalf("OnEnter() error = %v", err)
+	}
+	fsm.OnEnterAny(record("any"))
+	if err := fsm.OnSymbol("0", record("symbol")); err != nil {
+		t.Fatalf("OnSymbol() error = %v", err)
+	}
+
+	if _, err := fsm.GetFSMOutput("10"); err != nil {
+		t.Fatalf("GetFSMOutput() error = %v", err)
+	}
+	want := []string{
+		"exit S0-1->S1",
+		"any S0-1->S1",
+		"transition S1-0->S2",
+		"symbol S1-0->S2",
+		"enter S1-0->S2",
+		"any S1-0->S2",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("hook calls = %v, want %v", calls, want)
+	}
+}
+
+func TestFiniteStateMachine_HookVeto(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+
+	errForbidden := errors.New("S2 is forbidden")
+	fsm := threeModFA.NewFiniteStateMachine()
+	fsm.OnEnter("S2", func(symbol string, from string, to string) error { return errForbidden })
+
+	if err := fsm.ProcessInputRune("1"); err != nil {
+		t.Fatalf("ProcessInputRune() error = %v", err)
+	}
+	err := fsm.ProcessInputRune("0")
+	if !errors.Is(err, errForbidden) {
+		t.Errorf("ProcessInputRune() error = %v, want %v", err, errForbidden)
+	}
+	if fsm.CurrentState() != "S1" {
+		t.Errorf("CurrentState() = %v after veto, want S1", fsm.CurrentState())
+	}
+}
+
+func TestFiniteStateMachine_HookRegistrationErrors(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+	noop := func(symbol string, from string, to string) error { return nil }
+
+	fsm := threeModFA.NewFiniteStateMachine()
+	if err := fsm.OnEnter("S9", noop); err == nil {
+		t.Errorf("OnEnter() with unknown state expected error")
+	}
+	if err := fsm.OnExit("S9", noop); err == nil {
+		t.Errorf("OnExit() with unknown state expected error")
+	}
+	if err := fsm.OnTransition("S0", "2", noop); err == nil {
+		t.Errorf("OnTransition() with unknown symbol expected error")
+	}
+	if err := fsm.OnSymbol("2", noop); err == nil {
+		t.Errorf("OnSymbol() with unknown symbol expected error")
+	}
+	// "" is not a wildcard, it is rejected like any other unknown state
+	if err := fsm.OnEnter("", noop); err == nil {
+		t.Errorf("OnEnter() with unknown state \"\" expected error")
+	}
+}
+
+func TestFiniteStateMachine_HookEmptyStateName(t *testing.T) {
+
+	// a state named "" only fires its own hooks
+	fa, _ := NewFiniteAutomaton(NewSet("", "S1"), NewSet("a"), "S1", NewSet("S1"),
+		map[string]map[string]string{
+			"":   {"a": "S1"},
+			"S1": {"a": ""},
+		})
+	fsm := fa.NewFiniteStateMachine()
+	entered := 0
+	if err := fsm.OnEnter("", func(symbol string, from string, to string) error { entered++; return nil }); err != nil {
+		t.Fatalf("OnEnter() error = %v", err)
+	}
+	for _, r := range "aaa" {
+		fsm.ProcessInputRune(string(r))
+	}
+	if entered != 2 {
+		t.Errorf("OnEnter(\"\") hook fired %d times, want 2", entered)
+	}
+}
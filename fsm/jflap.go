@@ -0,0 +1,183 @@
+package fsm
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// JFLAPPosition is where JFLAP draws a state
+type JFLAPPosition struct {
+	X, Y float64
+}
+
+// jffState is a state of a JFLAP file
+type jffState struct {
+	ID      string    `xml:"id,attr"`
+	Name    string    `xml:"name,attr"`
+	X       *float64  `xml:"x"`
+	Y       *float64  `xml:"y"`
+	Label   string    `xml:"label,omitempty"`
+	Initial *struct{} `xml:"initial"`
+	Final   *struct{} `xml:"final"`
+}
+
+// jffTransition is a transition of a JFLAP file. Read is empty or missing for λ transitions
+type jffTransition struct {
+	From string  `xml:"from"`
+	To   string  `xml:"to"`
+	Read *string `xml:"read"`
+}
+
+// jffAutomaton holds the states and transitions of a JFLAP file
+type jffAutomaton struct {
+	States      []jffState      `xml:"state"`
+	Transitions []jffTransition `xml:"transition"`
+}
+
+// jffStructure is the root of a JFLAP file. JFLAP 7 nests the automaton, older versions put states directly in the structure
+type jffStructure struct {
+	XMLName   xml.Name      `xml:"structure"`
+	Type      string        `xml:"type"`
+	Automaton *jffAutomaton `xml:"automaton"`
+	jffAutomaton
+}
+
+// ReadJFLAP reads a DFA from a JFLAP .jff file, along with the position of its states when the file has them.
+//
+//	Only finite automata (type fa) that are deterministic and complete can be read. λ transitions, several transitions on the
+//	same input and missing transitions are reported with the JFLAP id of the state. States are named after the JFLAP name
+func ReadJFLAP(r io.Reader) (*FiniteAutomaton, map[string]JFLAPPosition, error) {
+	var structure jffStructure
+	if err := xml.NewDecoder(r).Decode(&structure); err != nil {
+		return nil, nil, fmt.Errorf("could not parse JFLAP file. Error: %v", err)
+	}
+	if structure.Type != "fa" {
+		return nil, nil, fmt.Errorf("JFLAP type %q is not supported, only fa (finite automaton)", structure.Type)
+	}
+	automaton := structure.jffAutomaton
+	if structure.Automaton != nil {
+		automaton = *structure.Automaton
+	}
+
+	names := make(map[string]string, len(automaton.States)) // JFLAP id to state name
+	ids := make(map[string]string, len(automaton.States))   // state name to JFLAP id
+	positions := make(map[string]JFLAPPosition)
+	Q, F := NewSet[string](), NewSet[string]()
+	var initial []string
+	for _, s := range automaton.States {
+		if _, exists := names[s.ID]; exists {
+			return nil, nil, fmt.Errorf("JFLAP state id %s is used twice", s.ID)
+		}
+		name := s.Name
+		if name == "" {
+			name = "q" + s.ID
+		}
+		if other, exists := ids[name]; exists {
+			return nil, nil, fmt.Errorf("JFLAP states %s and %s have the same name %s", other, s.ID, name)
+		}
+		names[s.ID], ids[name] = name, s.ID
+		Q.Add(name)
+		if s.Initial != nil {
+			initial = append(initial, s.ID)
+		}
+		if s.Final != nil {
+			F.Add(name)
+		}
+		if s.X != nil && s.Y != nil {
+			positions[name] = JFLAPPosition{X: *s.X, Y: *s.Y}
+		}
+	}
+	if len(initial) == 0 {
+		return nil, nil, fmt.Errorf("JFLAP file has no initial state")
+	}
+	if len(initial) > 1 {
+		return nil, nil, fmt.Errorf("JFLAP states %s are all initial, only one is allowed", strings.Join(initial, ", "))
+	}
+
+	Sigma := NewSet[string]()
+	delta := make(map[string]map[string]string)
+	targets := make(map[string]map[string]string) // targets keeps the JFLAP id of every transition target, for error messages
+	for _, t := range automaton.Transitions {
+		from, exists := names[t.From]
+		if !exists {
+			return nil, nil, fmt.Errorf("JFLAP transition from unknown state %s to state %s", t.From, t.To)
+		}
+		to, exists := names[t.To]
+		if !exists {
+			return nil, nil, fmt.Errorf("JFLAP transition from state %s goes to unknown state %s", t.From, t.To)
+		}
+		if t.Read == nil || *t.Read == "" {
+			return nil, nil, fmt.Errorf("JFLAP state %s (%s) has a λ transition to state %s, only DFAs are supported", t.From, from, t.To)
+		}
+		if delta[from] == nil {
+			delta[from], targets[from] = make(map[string]string), make(map[string]string)
+		}
+		if previous, exists := delta[from][*t.Read]; exists && previous != to {
+			return nil, nil, fmt.Errorf("JFLAP state %s (%s) has transitions on %s to states %s and %s, only DFAs are supported", t.From, from, *t.Read, targets[from][*t.Read], t.To)
+		}
+		delta[from][*t.Read], targets[from][*t.Read] = to, t.To
+		Sigma.Add(*t.Read)
+	}
+	for _, s := range automaton.States {
+		var missing []string
+		for _, input := range sortedStrings(Sigma) {
+			if _, exists := delta[names[s.ID]][input]; !exists {
+				missing = append(missing, input)
+			}
+		}
+		if len(missing) > 0 {
+			return nil, nil, fmt.Errorf("JFLAP state %s (%s) has no transition on %s, the DFA must be complete", s.ID, names[s.ID], strings.Join(missing, ", "))
+		}
+	}
+
+	fa, err := NewFiniteAutomaton(Q, Sigma, names[initial[0]], F, delta)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fa, positions, nil
+}
+
+// WriteJFLAP writes the FA as a JFLAP .jff file. States are given JFLAP ids 0, 1, ... in sorted order and drawn at their
+// position in positions. States without a position, or all of them if positions is nil, are placed on a circle
+func (f *FiniteAutomaton) WriteJFLAP(w io.Writer, positions map[string]JFLAPPosition) error {
+	circle := f.circularPositions()
+	states := sortedStrings(f.Q)
+	ids := make(map[string]string, len(states))
+	automaton := jffAutomaton{}
+	for i, state := range states {
+		ids[state] = strconv.Itoa(i)
+		p, exists := positions[state]
+		if !exists {
+			// circularPositions is centered on the origin, JFLAP coordinates are positive
+			p = JFLAPPosition{X: math.Round(circle[state].x) + 300, Y: math.Round(circle[state].y) + 300}
+		}
+		s := jffState{ID: ids[state], Name: state, X: &p.X, Y: &p.Y}
+		if state == f.q0 {
+			s.Initial = &struct{}{}
+		}
+		if f.F.Contains(state) {
+			s.Final = &struct{}{}
+		}
+		automaton.States = append(automaton.States, s)
+	}
+	for _, from := range states {
+		for _, input := range sortedStrings(f.Sigma) {
+			read := input
+			automaton.Transitions = append(automaton.Transitions, jffTransition{From: ids[from], To: ids[f.Delta[from][input]], Read: &read})
+		}
+	}
+
+	data, err := xml.MarshalIndent(jffStructure{Type: "fa", Automaton: &automaton}, "", "\t")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>`+"\n"); err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,165 @@
+package fsm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// endsInOneJFF is a JFLAP 7 file of a DFA accepting binary strings ending in 1
+const endsInOneJFF = `<?xml version="1.0" encoding="UTF-8" standalone="no"?><!--Created with JFLAP 7.1.--><structure>
+	<type>fa</type>
+	<automaton>
+		<!--The list of states.-->
+		<state id="0" name="q0">
+			<x>86.0</x>
+			<y>150.0</y>
+			<initial/>
+		</state>
+		<state id="1" name="q1">
+			<x>240.5</x>
+			<y>150.0</y>
+			<label>seen 1</label>
+			<final/>
+		</state>
+		<!--The list of transitions.-->
+		<transition>
+			<from>0</from>
+			<to>0</to>
+			<read>0</read>
+		</transition>
+		<transition>
+			<from>0</from>
+			<to>1</to>
+			<read>1</read>
+		</transition>
+		<transition>
+			<from>1</from>
+			<to>0</to>
+			<read>0</read>
+		</transition>
+		<transition>
+			<from>1</from>
+			<to>1</to>
+			<read>1</read>
+		</transition>
+	</automaton>
+</structure>`
+
+func TestReadJFLAP(t *testing.T) {
+
+	endsInOneFA, _ := NewFiniteAutomaton(
+		NewSet("q0", "q1"),
+		NewSet("0", "1"), "q0", NewSet("q1"),
+		map[string]map[string]string{
+			"q0": {"0": "q0", "1": "q1"},
+			"q1": {"0": "q0", "1": "q1"},
+		})
+	wantPositions := map[string]JFLAPPosition{"q0": {86, 150}, "q1": {240.5, 150}}
+
+	fa, positions, err := ReadJFLAP(strings.NewReader(endsInOneJFF))
+	if err != nil {
+		t.Fatalf("ReadJFLAP() error = %v", err)
+	}
+	if !fa.Equals(endsInOneFA) {
+		t.Errorf("ReadJFLAP() = %v, want %v", fa, endsInOneFA)
+	}
+	if !reflect.DeepEqual(positions, wantPositions) {
+		t.Errorf("ReadJFLAP() positions = %v, want %v", positions, wantPositions)
+	}
+
+	// round trip keeps the automaton and the coordinates
+	var b strings.Builder
+	if err := fa.WriteJFLAP(&b, positions); err != nil {
+		t.Fatalf("WriteJFLAP() error = %v", err)
+	}
+	again, againPositions, err := ReadJFLAP(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("ReadJFLAP() of WriteJFLAP() error = %v\n%s", err, b.String())
+	}
+	if !again.Equals(endsInOneFA) || !reflect.DeepEqual(againPositions, wantPositions) {
+		t.Errorf("ReadJFLAP() of WriteJFLAP() = %v, %v, want %v, %v", again, againPositions, endsInOneFA, wantPositions)
+	}
+
+	// older JFLAP versions put states directly in the structure, and states may have no name or position
+	old := `<structure><type>fa</type>
+		<state id="0"><initial/><final/></state>
+		<transition><from>0</from><to>0</to><read>a</read></transition>
+	</structure>`
+	fa, positions, err = ReadJFLAP(strings.NewReader(old))
+	if err != nil {
+		t.Fatalf("ReadJFLAP() of old file error = %v", err)
+	}
+	if !fa.Q.Contains("q0") || len(positions) != 0 {
+		t.Errorf("ReadJFLAP() of old file = %v, %v", fa, positions)
+	}
+}
+
+func TestFiniteAutomaton_WriteJFLAPWithoutPositions(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+	var b strings.Builder
+	if err := threeModFA.WriteJFLAP(&b, nil); err != nil {
+		t.Fatalf("WriteJFLAP() error = %v", err)
+	}
+	fa, positions, err := ReadJFLAP(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("ReadJFLAP() error = %v", err)
+	}
+	if !fa.Equals(threeModFA) {
+		t.Errorf("ReadJFLAP() = %v, want %v", fa, threeModFA)
+	}
+	for state, p := range positions {
+		if p.X < 0 || p.Y < 0 {
+			t.Errorf("WriteJFLAP() placed %s at %v, want positive coordinates", state, p)
+		}
+	}
+	if len(positions) != 3 {
+		t.Errorf("WriteJFLAP() placed %d states, want 3", len(positions))
+	}
+}
+
+func TestReadJFLAP_Errors(t *testing.T) {
+
+	// file builds a JFLAP file with two states, 0 initial and 1 final, and the given transitions
+	file := func(transitions string) string {
+		return `<structure><type>fa</type><automaton>
+			<state id="0" name="A"><initial/></state><state id="1" name="B"><final/></state>` + transitions + `</automaton></structure>`
+	}
+	transition := func(from, to, read string) string {
+		return "<transition><from>" + from + "</from><to>" + to + "</to>" + read + "</transition>"
+	}
+	complete := transition("0", "1", "<read>a</read>") + transition("1", "1", "<read>a</read>")
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "not xml", data: "<structure>", wantErr: "could not parse JFLAP file"},
+		{name: "pushdown automaton", data: `<structure><type>pda</type></structure>`, wantErr: `JFLAP type "pda" is not supported`},
+		{name: "turing machine", data: `<structure><type>turing</type></structure>`, wantErr: `JFLAP type "turing" is not supported`},
+		{name: "λ transition", data: file(complete + transition("0", "1", "<read/>")), wantErr: "JFLAP state 0 (A) has a λ transition to state 1"},
+		{name: "nondeterministic", data: file(complete + transition("1", "0", "<read>a</read>")), wantErr: "JFLAP state 1 (B) has transitions on a to states 1 and 0"},
+		{name: "incomplete", data: file(complete + transition("0", "0", "<read>b</read>")), wantErr: "JFLAP state 1 (B) has no transition on b"},
+		{name: "unknown target", data: file(complete + transition("0", "7", "<read>a</read>")), wantErr: "goes to unknown state 7"},
+		{name: "no initial state", data: `<structure><type>fa</type><state id="0"/></structure>`, wantErr: "no initial state"},
+		{name: "two initial states", data: `<structure><type>fa</type><state id="0"><initial/></state><state id="4"><initial/></state></structure>`, wantErr: "JFLAP states 0, 4 are all initial"},
+		{name: "duplicate id", data: `<structure><type>fa</type><state id="0"/><state id="0"/></structure>`, wantErr: "JFLAP state id 0 is used twice"},
+		{name: "duplicate name", data: `<structure><type>fa</type><state id="0" name="A"/><state id="1" name="A"/></structure>`, wantErr: "JFLAP states 0 and 1 have the same name A"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ReadJFLAP(strings.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadJFLAP() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package fsm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// MarshalJSON encodes the set as a JSON array, sorted so the output is deterministic and diff friendly
+func (s Set[T]) MarshalJSON() ([]byte, error) {
+	encoded := make([][]byte, 0, len(s))
+	for k := range s {
+		e, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, e)
+	}
+	sort.Slice(encoded, func(i, j int) bool { return bytes.Compare(encoded[i], encoded[j]) < 0 })
+	return append(append([]byte("["), bytes.Join(encoded, []byte(","))...), ']'), nil
+}
+
+// UnmarshalJSON decodes a JSON array into the set, replacing its content
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	var elements []T
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return err
+	}
+	*s = NewSet(elements...)
+	return nil
+}
+
+// jsonTransition is one entry of Delta in the JSON format of a FiniteAutomaton
+type jsonTransition struct {
+	From  string `json:"from"`
+	Input string `json:"input"`
+	To    string `json:"to"`
+}
+
+// jsonFiniteAutomaton is the JSON format of a FiniteAutomaton
+type jsonFiniteAutomaton struct {
+	States      Set[string]      `json:"states"`
+	Alphabet    Set[string]      `json:"alphabet"`
+	Initial     string           `json:"initial"`
+	Accepting   Set[string]      `json:"accepting"`
+	Transitions []jsonTransition `json:"transitions"`
+}
+
+// MarshalJSON encodes the FA with the following schema. All arrays are sorted, transitions by from then input:
+//
+//	{
+//	  "states":      ["S0", "S1"],
+//	  "alphabet":    ["0", "1"],
+//	  "initial":     "S0",
+//	  "accepting":   ["S1"],
+//	  "transitions": [{"from": "S0", "input": "0", "to": "S0"}, ...]
+//	}
+func (f *FiniteAutomaton) MarshalJSON() ([]byte, error) {
+	j := jsonFiniteAutomaton{States: f.Q, Alphabet: f.Sigma, Initial: f.q0, Accepting: f.F, Transitions: []jsonTransition{}}
+	for _, from := range sortedStrings(f.Q) {
+		inputs := make([]string, 0, len(f.Delta[from]))
+		for input := range f.Delta[from] {
+			inputs = append(inputs, input)
+		}
+		sort.Strings(inputs)
+		for _, input := range inputs {
+			j.Transitions = append(j.Transitions, jsonTransition{From: from, Input: input, To: f.Delta[from][input]})
+		}
+	}
+	return json.Marshal(j)
+}
+
+// jsonFiniteAutomatonInput is jsonFiniteAutomaton as decoded, with plain lists so duplicates can be reported
+type jsonFiniteAutomatonInput struct {
+	States      []string         `json:"states"`
+	Alphabet    []string         `json:"alphabet"`
+	Initial     string           `json:"initial"`
+	Accepting   []string         `json:"accepting"`
+	Transitions []jsonTransition `json:"transitions"`
+}
+
+// jsonSet returns the elements of the JSON array field as a set, or an error naming the first element listed twice
+func jsonSet(field string, elements []string) (Set[string], error) {
+	s := NewSet[string]()
+	for _, e := range elements {
+		if s.Contains(e) {
+			return nil, fmt.Errorf("%s lists %q twice", field, e)
+		}
+		s.Add(e)
+	}
+	return s, nil
+}
+
+// UnmarshalJSON decodes an FA in the format written by MarshalJSON. The result goes through the same validation as NewFiniteAutomaton.
+//
+//	Elements listed twice in states, alphabet or accepting are errors too
+func (f *FiniteAutomaton) UnmarshalJSON(data []byte) error {
+	var j jsonFiniteAutomatonInput
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	Q, err := jsonSet("states", j.States)
+	if err != nil {
+		return err
+	}
+	Sigma, err := jsonSet("alphabet", j.Alphabet)
+	if err != nil {
+		return err
+	}
+	F, err := jsonSet("accepting", j.Accepting)
+	if err != nil {
+		return err
+	}
+	delta := make(map[string]map[string]string)
+	for _, t := range j.Transitions {
+		if delta[t.From] == nil {
+			delta[t.From] = make(map[string]string)
+		}
+		if previous, exists := delta[t.From][t.Input]; exists {
+			return fmt.Errorf("transition from %s on input %s is defined twice (to %s and %s)", t.From, t.Input, previous, t.To)
+		}
+		delta[t.From][t.Input] = t.To
+	}
+	fa, err := NewFiniteAutomaton(Q, Sigma, j.Initial, F, delta)
+	if err != nil {
+		return err
+	}
+	*f = *fa
+	return nil
+}
@@ -0,0 +1,81 @@
+package fsm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFiniteAutomaton_JSON(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+
+	data, err := json.Marshal(threeModFA)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"states":["S0","S1","S2"],"alphabet":["0","1"],"initial":"S0","accepting":["S0","S1","S2"],"transitions":[` +
+		`{"from":"S0","input":"0","to":"S0"},{"from":"S0","input":"1","to":"S1"},` +
+		`{"from":"S1","input":"0","to":"S2"},{"from":"S1","input":"1","to":"S0"},` +
+		`{"from":"S2","input":"0","to":"S1"},{"from":"S2","input":"1","to":"S2"}]}`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = \n%s\n want \n%s", data, want)
+	}
+
+	var got FiniteAutomaton
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !got.Equals(threeModFA) {
+		t.Errorf("json.Unmarshal() = %v, want %v", &got, threeModFA)
+	}
+}
+
+func TestFiniteAutomaton_UnmarshalJSONErrors(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "not json", data: `{`, wantErr: "unexpected end of JSON input"},
+		{name: "initial not in states", data: `{"states":["S0"],"alphabet":["a"],"initial":"S1","accepting":["S0"],"transitions":[{"from":"S0","input":"a","to":"S0"}]}`, wantErr: "q0(initial state)"},
+		{name: "missing transition", data: `{"states":["S0","S1"],"alphabet":["a"],"initial":"S0","accepting":["S0"],"transitions":[{"from":"S0","input":"a","to":"S0"}]}`, wantErr: "delta doesn't contain all the possible state possibilities"},
+		{name: "duplicate transition", data: `{"states":["S0"],"alphabet":["a"],"initial":"S0","accepting":["S0"],"transitions":[{"from":"S0","input":"a","to":"S0"},{"from":"S0","input":"a","to":"S0"}]}`, wantErr: "defined twice"},
+		{name: "no states", data: `{"alphabet":["a"],"initial":"S0","accepting":["S0"]}`, wantErr: "Q(list of acceptable states) is empty"},
+		{name: "unknown target", data: `{"states":["S0"],"alphabet":["a"],"initial":"S0","accepting":["S0"],"transitions":[{"from":"S0","input":"a","to":"S9"}]}`, wantErr: "on input a goes to S9, which is not one of the acceptable states"},
+		{name: "duplicate state", data: `{"states":["S0","S0"],"alphabet":["a"],"initial":"S0","accepting":["S0"],"transitions":[{"from":"S0","input":"a","to":"S0"}]}`, wantErr: `states lists "S0" twice`},
+		{name: "duplicate input", data: `{"states":["S0"],"alphabet":["a","a"],"initial":"S0","accepting":["S0"],"transitions":[{"from":"S0","input":"a","to":"S0"}]}`, wantErr: `alphabet lists "a" twice`},
+		{name: "duplicate accepting", data: `{"states":["S0"],"alphabet":["a"],"initial":"S0","accepting":["S0","S0"],"transitions":[{"from":"S0","input":"a","to":"S0"}]}`, wantErr: `accepting lists "S0" twice`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fa FiniteAutomaton
+			if err := json.Unmarshal([]byte(tt.data), &fa); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("json.Unmarshal() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSet_JSON(t *testing.T) {
+
+	data, err := json.Marshal(NewSet(3, 1, 2))
+	if err != nil || string(data) != "[1,2,3]" {
+		t.Errorf("json.Marshal() = %s, %v, want [1,2,3]", data, err)
+	}
+	var s Set[string]
+	if err := json.Unmarshal([]byte(`["b","a","b"]`), &s); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if s.Size() != 2 || !s.Contains("a") || !s.Contains("b") {
+		t.Errorf("json.Unmarshal() = %v, want (a, b)", s)
+	}
+}
@@ -0,0 +1,179 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MealyMachine represents a Mealy machine (finite-state transducer): a FiniteAutomaton where every transition also emits an output string.
+//
+//	Running the machine concatenates the outputs of the transitions taken. Outputs can be empty or longer than one rune. F is not used by Run
+type MealyMachine struct {
+	FA    *FiniteAutomaton             // FA represents the FiniteAutomaton that drives the machine
+	Omega map[string]map[string]string // Omega is the output function ω, a map between state, input and the output emitted by that transition
+}
+
+// NewMealyMachine creates a new MealyMachine from fa and ω. Does initial error checking as well: ω has to cover every transition of Delta
+func NewMealyMachine(fa *FiniteAutomaton, omega map[string]map[string]string) (*MealyMachine, error) {
+	if fa == nil {
+		return nil, fmt.Errorf("FA is nil")
+	}
+	m := MealyMachine{FA: fa, Omega: make(map[string]map[string]string, len(omega))}
+
+	// deep copy omega and error check in the process
+	for state, bySymbol := range omega {
+		if !fa.Q.Contains(state) {
+			return nil, fmt.Errorf("ω(omega) state %s is not one of the acceptable states", state)
+		}
+		m.Omega[state] = make(map[string]string, len(bySymbol))
+		for symbol, output := range bySymbol {
+			if !fa.Sigma.Contains(symbol) {
+				return nil, fmt.Errorf("ω(omega) input %s of state %s is not an acceptable input", symbol, state)
+			}
+			m.Omega[state][symbol] = output
+		}
+	}
+	for _, state := range sortedStrings(fa.Q) {
+		for _, symbol := range sortedStrings(fa.Sigma) {
+			if _, exists := m.Omega[state][symbol]; !exists {
+				return nil, fmt.Errorf("ω(omega) doesn't contain an output for state %s on input %s", state, symbol)
+			}
+		}
+	}
+	return &m, nil
+}
+
+// String returns a string representing the MealyMachine as a string
+func (m *MealyMachine) String() string {
+	return fmt.Sprintf("Mealy: \n\tFA=%s\n\tω=%v\n", m.FA.String(), m.Omega)
+}
+
+// Run processes input from the initial state and returns the concatenated output of the transitions taken
+func (m *MealyMachine) Run(input string) (string, error) {
+	_, output, err := m.runFrom(m.FA.q0, input)
+	return output, err
+}
+
+// step takes a single transition and returns the next state and the output emitted
+func (m *MealyMachine) step(state string, inputRune string) (string, string, error) {
+	if !m.FA.Sigma.Contains(inputRune) {
+		return "", "", fmt.Errorf("rune %v is not an acceptable input", inputRune)
+	}
+	return m.FA.Delta[state][inputRune], m.Omega[state][inputRune], nil
+}
+
+// runFrom processes input starting from state and returns the state reached and the output emitted
+func (m *MealyMachine) runFrom(state string, input string) (string, string, error) {
+	var sb strings.Builder
+	for _, r := range input {
+		next, output, err := m.step(state, string(r))
+		if err != nil {
+			return "", "", err
+		}
+		sb.WriteString(output)
+		state = next
+	}
+	return state, sb.String(), nil
+}
+
+// Compose returns the transducer equivalent to feeding the output of m into other, i.e. other(m(input)).
+//
+//	States of the result are the reachable pairs "(p,q)" of m and other states. Run doesn't use F, so every pair is final.
+//	Returns an error if m can emit a rune that is not in other's Sigma
+func (m *MealyMachine) Compose(other *MealyMachine) (*MealyMachine, error) {
+	type pair struct{ p, q string }
+
+	Q := NewSet[string]()
+	F := NewSet[string]()
+	Delta := make(map[string]map[string]string)
+	Omega := make(map[string]map[string]string)
+
+	start := pair{m.FA.q0, other.FA.q0}
+	queue := []pair{start}
+	Q.Add(tupleState(start.p, start.q))
+	symbols := sortedStrings(m.FA.Sigma)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		name := tupleState(current.p, current.q)
+		F.Add(name)
+		Delta[name] = make(map[string]string, len(symbols))
+		Omega[name] = make(map[string]string, len(symbols))
+		for _, symbol := range symbols {
+			p, intermediate, _ := m.step(current.p, symbol) // symbol is in m's Sigma, can't fail
+			q, output, err := other.runFrom(current.q, intermediate)
+			if err != nil {
+				return nil, fmt.Errorf("could not compose transducers, output %q of %s on %s is not accepted by the second transducer. Error: %v", intermediate, current.p, symbol, err)
+			}
+			next := pair{p, q}
+			nextName := tupleState(p, q)
+			if !Q.Contains(nextName) {
+				Q.Add(nextName)
+				queue = append(queue, next)
+			}
+			Delta[name][symbol] = nextName
+			Omega[name][symbol] = output
+		}
+	}
+	fa, err := NewFiniteAutomaton(Q, m.FA.Sigma, tupleState(start.p, start.q), F, Delta)
+	if err != nil {
+		return nil, err
+	}
+	return NewMealyMachine(fa, Omega)
+}
+
+// MooreToMealy converts a Moore machine with string outputs into an equivalent Mealy machine, where each transition emits λ of its target state.
+//
+//	The Mealy machine has the same FA. The Moore output λ(q0) of the empty prefix has no transition to live on and is dropped
+func MooreToMealy(moore *MooreMachine[string]) (*MealyMachine, error) {
+	omega := make(map[string]map[string]string, len(moore.FA.Delta))
+	for state, bySymbol := range moore.FA.Delta {
+		omega[state] = make(map[string]string, len(bySymbol))
+		for symbol, next := range bySymbol {
+			omega[state][symbol] = moore.Lambda[next]
+		}
+	}
+	return NewMealyMachine(moore.FA, omega)
+}
+
+// ToMoore converts the Mealy machine into an equivalent Moore machine.
+//
+//	States of the result are the reachable pairs "(q,o)" of a state and the output of the transition that entered it.
+//	The initial state is "(q0,\"\")" with an empty output, so the Moore outputs concatenated give the Mealy output.
+//	Run doesn't use F, so every pair is final, even when no final state of m is reachable
+func (m *MealyMachine) ToMoore() (*MooreMachine[string], error) {
+	type pair struct{ state, output string }
+
+	Q := NewSet[string]()
+	F := NewSet[string]()
+	Delta := make(map[string]map[string]string)
+	lambda := make(map[string]string)
+
+	start := pair{m.FA.q0, ""}
+	queue := []pair{start}
+	Q.Add(tupleState(start.state, start.output))
+	symbols := sortedStrings(m.FA.Sigma)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		name := tupleState(current.state, current.output)
+		lambda[name] = current.output
+		F.Add(name)
+		Delta[name] = make(map[string]string, len(symbols))
+		for _, symbol := range symbols {
+			next := pair{m.FA.Delta[current.state][symbol], m.Omega[current.state][symbol]}
+			nextName := tupleState(next.state, next.output)
+			if !Q.Contains(nextName) {
+				Q.Add(nextName)
+				queue = append(queue, next)
+			}
+			Delta[name][symbol] = nextName
+		}
+	}
+
+	fa, err := NewFiniteAutomaton(Q, m.FA.Sigma, tupleState(start.state, start.output), F, Delta)
+	if err != nil {
+		return nil, err
+	}
+	return NewMooreMachine(fa, lambda)
+}
@@ -0,0 +1,207 @@
+package fsm
+
+import (
+	"strings"
+	"testing"
+)
+
+// newUpperMealy returns a transducer over (a, b, " ") that upper-cases letters and squeezes repeated spaces
+func newUpperMealy() *MealyMachine {
+	fa, _ := NewFiniteAutomaton(
+		NewSet("word", "space"),
+		NewSet("a", "b", " "), "word", NewSet("word", "space"),
+		map[string]map[string]string{
+			"word":  {"a": "word", "b": "word", " ": "space"},
+			"space": {"a": "word", "b": "word", " ": "space"},
+		})
+	m, _ := NewMealyMachine(fa, map[string]map[string]string{
+		"word":  {"a": "A", "b": "B", " ": " "},
+		"space": {"a": "A", "b": "B", " ": ""},
+	})
+	return m
+}
+
+// newSwapMealy returns a transducer over (A, B, " ") that swaps A and B and turns spaces into "_"
+func newSwapMealy() *MealyMachine {
+	fa, _ := NewFiniteAutomaton(
+		NewSet("S0"),
+		NewSet("A", "B", " "), "S0", NewSet("S0"),
+		map[string]map[string]string{
+			"S0": {"A": "S0", "B": "S0", " ": "S0"},
+		})
+	m, _ := NewMealyMachine(fa, map[string]map[string]string{
+		"S0": {"A": "B", "B": "A", " ": "_"},
+	})
+	return m
+}
+
+func TestMealyMachine_Run(t *testing.T) {
+
+	upper := newUpperMealy()
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty", input: "", want: ""},
+		{name: "squeeze spaces", input: "ab   ba b", want: "AB BA B"},
+		{name: "bad input", input: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := upper.Run(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MealyMachine.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("MealyMachine.Run() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMealyMachine_Compose(t *testing.T) {
+
+	upper, swap := newUpperMealy(), newSwapMealy()
+	composed, err := upper.Compose(swap)
+	if err != nil {
+		t.Fatalf("MealyMachine.Compose() error = %v", err)
+	}
+	for _, input := range []string{"", "a", "ab  b", "  ba a  "} {
+		intermediate, _ := upper.Run(input)
+		want, _ := swap.Run(intermediate)
+		if got, err := composed.Run(input); err != nil || got != want {
+			t.Errorf("composed.Run(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+
+	// swap emits "A", "B" and "_", upper doesn't accept "_"
+	if _, err := swap.Compose(upper); err == nil {
+		t.Errorf("MealyMachine.Compose() expected error for incompatible alphabets")
+	}
+
+	// F of the transducers doesn't matter, even if no pair of final states is reachable
+	unreachableFA, _ := NewFiniteAutomaton(NewSet("S0", "S1"), NewSet("A", "B", "_"), "S0", NewSet("S1"),
+		map[string]map[string]string{
+			"S0": {"A": "S0", "B": "S0", "_": "S0"},
+			"S1": {"A": "S1", "B": "S1", "_": "S1"},
+		})
+	lower, _ := NewMealyMachine(unreachableFA, map[string]map[string]string{
+		"S0": {"A": "a", "B": "b", "_": " "},
+		"S1": {"A": "a", "B": "b", "_": " "},
+	})
+	composed, err = swap.Compose(lower)
+	if err != nil {
+		t.Fatalf("MealyMachine.Compose() error = %v", err)
+	}
+	if got, err := composed.Run("AB A"); err != nil || got != "ba b" {
+		t.Errorf("composed.Run() = %q, %v, want %q", got, err, "ba b")
+	}
+}
+
+func TestMealyMachine_MooreConversions(t *testing.T) {
+
+	upper := newUpperMealy()
+	moore, err := upper.ToMoore()
+	if err != nil {
+		t.Fatalf("MealyMachine.ToMoore() error = %v", err)
+	}
+	back, err := MooreToMealy(moore)
+	if err != nil {
+		t.Fatalf("MooreToMealy() error = %v", err)
+	}
+	for _, input := range []string{"", "a", "ab  b", "  ba a  "} {
+		want, _ := upper.Run(input)
+		outputs, err := moore.Run(input)
+		if err != nil || strings.Join(outputs, "") != want {
+			t.Errorf("moore.Run(%q) = %q, %v, want %q", input, strings.Join(outputs, ""), err, want)
+		}
+		if got, err := back.Run(input); err != nil || got != want {
+			t.Errorf("back.Run(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+}
+
+func TestMealyMachine_ToMooreUnreachableFinal(t *testing.T) {
+
+	// F of the Mealy machine doesn't matter, even if no final state is reachable from q0
+	fa, _ := NewFiniteAutomaton(
+		NewSet("A", "B"),
+		NewSet("x"), "A", NewSet("B"),
+		map[string]map[string]string{
+			"A": {"x": "A"},
+			"B": {"x": "B"},
+		})
+	m, _ := NewMealyMachine(fa, map[string]map[string]string{
+		"A": {"x": "y"},
+		"B": {"x": "z"},
+	})
+	moore, err := m.ToMoore()
+	if err != nil {
+		t.Fatalf("MealyMachine.ToMoore() error = %v", err)
+	}
+	outputs, err := moore.Run("xx")
+	if err != nil || strings.Join(outputs, "") != "yy" {
+		t.Errorf("moore.Run() = %q, %v, want %q", strings.Join(outputs, ""), err, "yy")
+	}
+}
+
+func TestMealyMachine_ToMooreQuotedNames(t *testing.T) {
+
+	// ("a,b","c") and ("a","b,c") would both be named "(a,b,c)" without quoting
+	fa, _ := NewFiniteAutomaton(
+		NewSet("a", "a,b"),
+		NewSet("x", "y"), "a", NewSet("a", "a,b"),
+		map[string]map[string]string{
+			"a":   {"x": "a,b", "y": "a"},
+			"a,b": {"x": "a,b", "y": "a"},
+		})
+	m, _ := NewMealyMachine(fa, map[string]map[string]string{
+		"a":   {"x": "c", "y": "b,c"},
+		"a,b": {"x": "c", "y": "b,c"},
+	})
+	moore, err := m.ToMoore()
+	if err != nil {
+		t.Fatalf("MealyMachine.ToMoore() error = %v", err)
+	}
+	want := NewSet(`(a,"")`, `("a,b",c)`, `(a,"b,c")`)
+	if !moore.FA.Q.IsSubset(want) || !want.IsSubset(moore.FA.Q) {
+		t.Errorf("ToMoore() Q = %v, want %v", moore.FA.Q, want)
+	}
+	mealyOutput, _ := m.Run("xyx")
+	outputs, err := moore.Run("xyx")
+	if err != nil || strings.Join(outputs, "") != mealyOutput {
+		t.Errorf("moore.Run() = %q, %v, want %q", strings.Join(outputs, ""), err, mealyOutput)
+	}
+}
+
+func TestNewMealyMachine(t *testing.T) {
+
+	fa, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S0", "1": "S1"},
+		})
+
+	tests := []struct {
+		name    string
+		omega   map[string]map[string]string
+		wantErr bool
+	}{
+		{name: "complete", omega: map[string]map[string]string{"S0": {"0": "a", "1": "b"}, "S1": {"0": "", "1": "c"}}},
+		{name: "missing transition", omega: map[string]map[string]string{"S0": {"0": "a", "1": "b"}, "S1": {"0": ""}}, wantErr: true},
+		{name: "unknown state", omega: map[string]map[string]string{"S0": {"0": "a", "1": "b"}, "S1": {"0": "", "1": "c"}, "S2": {"0": ""}}, wantErr: true},
+		{name: "unknown input", omega: map[string]map[string]string{"S0": {"0": "a", "1": "b", "2": "d"}, "S1": {"0": "", "1": "c"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMealyMachine(fa, tt.omega)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewMealyMachine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
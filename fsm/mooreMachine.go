@@ -0,0 +1,95 @@
+package fsm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MooreMachine represents a Moore machine: a FiniteAutomaton plus an output function λ: Q → Γ.
+//
+//	Unlike GetFSMOutput, running a Moore machine yields an output for every state visited, not just the final one. F is not used
+type MooreMachine[O any] struct {
+	FA     *FiniteAutomaton // FA represents the FiniteAutomaton that drives the machine
+	Lambda map[string]O     // Lambda is the output function λ, mapping every state in Q to its output
+}
+
+// NewMooreMachine creates a new MooreMachine from fa and λ. Does initial error checking as well: λ has to cover exactly Q
+func NewMooreMachine[O any](fa *FiniteAutomaton, lambda map[string]O) (*MooreMachine[O], error) {
+	if fa == nil {
+		return nil, fmt.Errorf("FA is nil")
+	}
+	m := MooreMachine[O]{FA: fa, Lambda: make(map[string]O, len(lambda))}
+	for state, output := range lambda {
+		if !fa.Q.Contains(state) {
+			return nil, fmt.Errorf("λ(lambda) state %s is not one of the acceptable states", state)
+		}
+		m.Lambda[state] = output
+	}
+
+	var missing []string
+	for state := range fa.Q {
+		if _, exists := m.Lambda[state]; !exists {
+			missing = append(missing, state)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing) // sort for a deterministic error message
+		return nil, fmt.Errorf("λ(lambda) doesn't cover states (%s)", strings.Join(missing, ", "))
+	}
+	return &m, nil
+}
+
+// String returns a string representing the MooreMachine as a string
+func (m *MooreMachine[O]) String() string {
+	return fmt.Sprintf("Moore: \n\tFA=%s\n\tλ=%v\n", m.FA.String(), m.Lambda)
+}
+
+// Run processes input from the initial state and returns the output sequence.
+//
+//	The sequence starts with λ(q0) followed by one output per input rune, so it is always len(input runes)+1 long
+func (m *MooreMachine[O]) Run(input string) ([]O, error) {
+	outputs := []O{}
+	err := m.RunFunc(input, func(output O) error {
+		outputs = append(outputs, output)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// RunFunc processes input like Run but hands every output to emit as soon as it is produced, which suits streaming pipelines.
+//
+//	An error from emit stops the run and is returned
+func (m *MooreMachine[O]) RunFunc(input string, emit func(O) error) error {
+	fsm := m.NewFiniteStateMachine()
+	if err := emit(m.Lambda[fsm.currentState]); err != nil {
+		return err
+	}
+	for _, r := range input {
+		if err := fsm.ProcessInputRune(string(r)); err != nil {
+			return err
+		}
+		if err := emit(m.Lambda[fsm.currentState]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewFiniteStateMachine returns a new FiniteStateMachine with initialized state for the underlying FA, so hooks and tracing can be used step by step
+func (m *MooreMachine[O]) NewFiniteStateMachine() *FiniteStateMachine {
+	return m.FA.NewFiniteStateMachine()
+}
+
+// Output returns λ(state)
+func (m *MooreMachine[O]) Output(state string) (O, error) {
+	output, exists := m.Lambda[state]
+	if !exists {
+		var zero O
+		return zero, fmt.Errorf("state %s is not one of the acceptable states", state)
+	}
+	return output, nil
+}
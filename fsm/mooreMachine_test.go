@@ -0,0 +1,80 @@
+package fsm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMooreMachine_Run(t *testing.T) {
+
+	// edge detector: outputs 1 when the last two bits differ
+	edgeFA, _ := NewFiniteAutomaton(
+		NewSet("start", "low", "high", "rise", "fall"),
+		NewSet("0", "1"), "start", NewSet("low", "high"),
+		map[string]map[string]string{
+			"start": {"0": "low", "1": "high"},
+			"low":   {"0": "low", "1": "rise"},
+			"high":  {"0": "fall", "1": "high"},
+			"rise":  {"0": "fall", "1": "high"},
+			"fall":  {"0": "low", "1": "rise"},
+		})
+	edges, err := NewMooreMachine(edgeFA, map[string]int{"start": 0, "low": 0, "high": 0, "rise": 1, "fall": 1})
+	if err != nil {
+		t.Fatalf("NewMooreMachine() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		{name: "empty input", input: "", want: []int{0}},
+		{name: "no edges", input: "000", want: []int{0, 0, 0, 0}},
+		{name: "edges", input: "00110", want: []int{0, 0, 0, 1, 0, 1}},
+		{name: "bad input", input: "012", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := edges.Run(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MooreMachine.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MooreMachine.Run() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMooreMachine(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+
+	tests := []struct {
+		name    string
+		fa      *FiniteAutomaton
+		lambda  map[string]string
+		wantErr bool
+	}{
+		{name: "covers Q", fa: threeModFA, lambda: map[string]string{"S0": "a", "S1": "b", "S2": "c"}},
+		{name: "missing state", fa: threeModFA, lambda: map[string]string{"S0": "a", "S1": "b"}, wantErr: true},
+		{name: "unknown state", fa: threeModFA, lambda: map[string]string{"S0": "a", "S1": "b", "S2": "c", "S3": "d"}, wantErr: true},
+		{name: "nil FA", fa: nil, lambda: map[string]string{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMooreMachine(tt.fa, tt.lambda)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewMooreMachine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package fsm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OutputMap maps states to typed output values. It replaces the int-only OutputConverter for automata whose states are not named "S<number>"
+type OutputMap[O any] map[string]O
+
+// NewOutputMap creates an OutputMap for fa. Every state in F must have an output, and every state with an output must be in Q
+func NewOutputMap[O any](fa *FiniteAutomaton, outputs map[string]O) (OutputMap[O], error) {
+	if fa == nil {
+		return nil, fmt.Errorf("FA is nil")
+	}
+	m := make(OutputMap[O], len(outputs))
+	for state, output := range outputs {
+		if !fa.Q.Contains(state) {
+			return nil, fmt.Errorf("output state %s is not one of the acceptable states", state)
+		}
+		m[state] = output
+	}
+
+	var missing []string
+	for state := range fa.F {
+		if _, exists := m[state]; !exists {
+			missing = append(missing, state)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing) // sort for a deterministic error message
+		return nil, fmt.Errorf("final states (%s) have no output", strings.Join(missing, ", "))
+	}
+	return m, nil
+}
+
+// Convert returns the output of state, or an error if the state has none
+func (m OutputMap[O]) Convert(state string) (O, error) {
+	output, exists := m[state]
+	if !exists {
+		var zero O
+		return zero, fmt.Errorf("state %s has no output", state)
+	}
+	return output, nil
+}
+
+// OutputConverterFromMap returns an OutputConverter for the int FiniteStateMachine backed by an OutputMap[int]
+func OutputConverterFromMap(m OutputMap[int]) func(string) (int, error) {
+	return m.Convert
+}
+
+// TypedFiniteStateMachine is a FiniteStateMachine whose output is a value of type O taken from an OutputMap.
+//
+//	The embedded FiniteStateMachine gives access to hooks, tracing and ProcessInputRune
+type TypedFiniteStateMachine[O any] struct {
+	*FiniteStateMachine
+	Outputs OutputMap[O] // Outputs maps the final states to the FSM output
+}
+
+// NewTypedFiniteStateMachine returns a new TypedFiniteStateMachine with initialized state. outputs is validated with NewOutputMap
+func NewTypedFiniteStateMachine[O any](fa *FiniteAutomaton, outputs map[string]O) (*TypedFiniteStateMachine[O], error) {
+	m, err := NewOutputMap(fa, outputs)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedFiniteStateMachine[O]{FiniteStateMachine: fa.NewFiniteStateMachine(), Outputs: m}, nil
+}
+
+// String returns a string representing the TypedFiniteStateMachine as a string
+func (f *TypedFiniteStateMachine[O]) String() string {
+	return fmt.Sprintf("TypedFSM: \n\tFA=%s\n\tOutputs=%v\n", f.FA.String(), map[string]O(f.Outputs))
+}
+
+// GetFSMOutput gets the typed output of the FSM based on the given inputs. returns an error if it encounters an error in processing
+func (f *TypedFiniteStateMachine[O]) GetFSMOutput(input string) (O, error) {
+	for _, r := range input {
+		if err := f.ProcessInputRune(string(r)); err != nil {
+			var zero O
+			return zero, err
+		}
+	}
+	return f.Output()
+}
+
+// GetFSMOutputContext is GetFSMOutput that stops when ctx is done. See FiniteStateMachine.GetFSMOutputContext
+func (f *TypedFiniteStateMachine[O]) GetFSMOutputContext(ctx context.Context, input string) (O, error) {
+	position := 0
+	for _, r := range input {
+		if err := f.checkContext(ctx, position); err != nil {
+			var zero O
+			return zero, err
+		}
+		if err := f.ProcessInputRune(string(r)); err != nil {
+			var zero O
+			return zero, err
+		}
+		position++
+	}
+	return f.Output()
+}
+
+// Output returns the typed output of the current state, or an error if it is not one of the accepted final states
+func (f *TypedFiniteStateMachine[O]) Output() (O, error) {
+	finalState, err := f.acceptedState()
+	if err != nil {
+		var zero O
+		return zero, err
+	}
+	return f.Outputs.Convert(finalState)
+}
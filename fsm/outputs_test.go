@@ -0,0 +1,85 @@
+package fsm
+
+import (
+	"testing"
+)
+
+// token is a struct output used by the typed output tests
+type token struct {
+	Kind  string
+	Valid bool
+}
+
+func TestTypedFiniteStateMachine_GetFSMOutput(t *testing.T) {
+
+	// recognizes identifiers (letter then letters/digits) and numbers (digits)
+	lexFA, _ := NewFiniteAutomaton(
+		NewSet("start", "ident", "number", "error"),
+		NewSet("a", "1"), "start", NewSet("ident", "number"),
+		map[string]map[string]string{
+			"start":  {"a": "ident", "1": "number"},
+			"ident":  {"a": "ident", "1": "ident"},
+			"number": {"a": "error", "1": "number"},
+			"error":  {"a": "error", "1": "error"},
+		})
+
+	names, err := NewTypedFiniteStateMachine(lexFA, map[string]string{"ident": "IDENT", "number": "NUMBER"})
+	if err != nil {
+		t.Fatalf("NewTypedFiniteStateMachine() error = %v", err)
+	}
+	if got, err := names.GetFSMOutput("a1a"); err != nil || got != "IDENT" {
+		t.Errorf("TypedFiniteStateMachine.GetFSMOutput() = %v, %v, want IDENT", got, err)
+	}
+
+	tokens, err := NewTypedFiniteStateMachine(lexFA, map[string]token{"ident": {Kind: "IDENT", Valid: true}, "number": {Kind: "NUMBER", Valid: true}})
+	if err != nil {
+		t.Fatalf("NewTypedFiniteStateMachine() error = %v", err)
+	}
+	if got, err := tokens.GetFSMOutput("11"); err != nil || got != (token{Kind: "NUMBER", Valid: true}) {
+		t.Errorf("TypedFiniteStateMachine.GetFSMOutput() = %v, %v, want NUMBER token", got, err)
+	}
+
+	rejected, _ := NewTypedFiniteStateMachine(lexFA, map[string]string{"ident": "IDENT", "number": "NUMBER"})
+	if got, err := rejected.GetFSMOutput("1a"); err == nil || got != "" {
+		t.Errorf("TypedFiniteStateMachine.GetFSMOutput() = %v, %v, want rejection", got, err)
+	}
+}
+
+func TestNewOutputMap(t *testing.T) {
+
+	lexFA, _ := NewFiniteAutomaton(
+		NewSet("start", "ident", "number"),
+		NewSet("a", "1"), "start", NewSet("ident", "number"),
+		map[string]map[string]string{
+			"start":  {"a": "ident", "1": "number"},
+			"ident":  {"a": "ident", "1": "ident"},
+			"number": {"a": "start", "1": "number"},
+		})
+
+	tests := []struct {
+		name    string
+		outputs map[string]int
+		wantErr bool
+	}{
+		{name: "all final states", outputs: map[string]int{"ident": 1, "number": 2}},
+		{name: "extra non final state", outputs: map[string]int{"ident": 1, "number": 2, "start": 0}},
+		{name: "missing final state", outputs: map[string]int{"ident": 1}, wantErr: true},
+		{name: "unknown state", outputs: map[string]int{"ident": 1, "number": 2, "other": 3}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewOutputMap(lexFA, tt.outputs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewOutputMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// an OutputMap[int] can back the int FiniteStateMachine too
+	m, _ := NewOutputMap(lexFA, map[string]int{"ident": 1, "number": 2})
+	fsm := lexFA.NewFiniteStateMachine()
+	fsm.OutputConverter = OutputConverterFromMap(m)
+	if got, err := fsm.GetFSMOutput("11"); err != nil || got != 2 {
+		t.Errorf("FiniteStateMachine.GetFSMOutput() = %v, %v, want 2", got, err)
+	}
+}
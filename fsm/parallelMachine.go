@@ -0,0 +1,195 @@
+package fsm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CompositionMode selects how the machines of a parallel composition share the events
+type CompositionMode int
+
+const (
+	// Synchronized delivers every event to all the machines that have it in their Sigma, which move together.
+	// Machines that don't know the event stay where they are. An event nobody knows is an error
+	Synchronized CompositionMode = iota
+	// Interleaved moves exactly one machine per event. Events are addressed to a machine with InterleavedSymbol
+	Interleaved
+)
+
+// InterleavedSymbol returns the event that delivers symbol to the machine at index in an Interleaved composition, as "(index,symbol)"
+func InterleavedSymbol(index int, symbol string) string {
+	return tupleState(strconv.Itoa(index), symbol)
+}
+
+// ParallelStateMachine runs several FiniteStateMachines over the same event stream, as orthogonal regions of one machine
+type ParallelStateMachine struct {
+	Machines []*FiniteStateMachine // Machines are the composed machines, in order
+	Mode     CompositionMode       // Mode selects synchronized or interleaved semantics
+	events   map[string][]route    // events maps every event to the machines and symbols it moves
+}
+
+// route is the symbol an event delivers to one machine
+type route struct {
+	machine int
+	symbol  string
+}
+
+// compositionEvents returns the events of a composition of machines with the given alphabets, and where they are routed
+func compositionEvents(mode CompositionMode, alphabets []Set[string]) map[string][]route {
+	events := make(map[string][]route)
+	for i, sigma := range alphabets {
+		for _, symbol := range sortedStrings(sigma) {
+			if mode == Interleaved {
+				events[InterleavedSymbol(i, symbol)] = []route{{i, symbol}}
+			} else {
+				events[symbol] = append(events[symbol], route{i, symbol})
+			}
+		}
+	}
+	return events
+}
+
+// NewParallelStateMachine composes machines in parallel. Returns an error if there are less than 2 machines
+func NewParallelStateMachine(mode CompositionMode, machines ...*FiniteStateMachine) (*ParallelStateMachine, error) {
+	if len(machines) < 2 {
+		return nil, fmt.Errorf("parallel composition needs at least 2 machines, got %d", len(machines))
+	}
+	alphabets := make([]Set[string], len(machines))
+	for i, m := range machines {
+		alphabets[i] = m.FA.Sigma
+	}
+	return &ParallelStateMachine{Machines: machines, Mode: mode, events: compositionEvents(mode, alphabets)}, nil
+}
+
+// String returns a string representing the ParallelStateMachine as a string
+func (p *ParallelStateMachine) String() string {
+	return fmt.Sprintf("ParallelFSM: \n\tstate=%s\n\tmachines=%d\n", p.StateName(), len(p.Machines))
+}
+
+// CurrentState returns the composite state, as the tuple of the current state of every machine
+func (p *ParallelStateMachine) CurrentState() []string {
+	states := make([]string, len(p.Machines))
+	for i, m := range p.Machines {
+		states[i] = m.currentState
+	}
+	return states
+}
+
+// StateName returns the composite state as "(s1,s2,...)", the name it has in the static product
+func (p *ParallelStateMachine) StateName() string {
+	return tupleState(p.CurrentState()...)
+}
+
+// ProcessInputRune delivers an event to the machines, according to Mode.
+//
+//	Every machine the event is routed to is checked first (input, guards and hooks), and they only move if none of them
+//	fails. A veto, for example, leaves all the machines in their previous state. Hooks of the machines checked before the
+//	failing one have already fired and are not undone
+func (p *ParallelStateMachine) ProcessInputRune(event string) error {
+	routes, known := p.events[event]
+	if !known {
+		return fmt.Errorf("event %v is not an acceptable input of any machine", event)
+	}
+	next := make([]string, len(routes))
+	for n, r := range routes {
+		state, err := p.Machines[r.machine].prepareTransition(r.symbol)
+		if err != nil {
+			return fmt.Errorf("machine %d: %w", r.machine, err)
+		}
+		next[n] = state
+	}
+	for n, r := range routes {
+		p.Machines[r.machine].takeTransition(r.symbol, next[n])
+	}
+	return nil
+}
+
+// Run delivers every rune of input as an event and returns the composite state. returns an error if a machine is not in
+// one of its final states at the end. Use ProcessInputRune for Interleaved events, which are longer than a rune
+func (p *ParallelStateMachine) Run(input string) ([]string, error) {
+	for _, r := range input {
+		if err := p.ProcessInputRune(string(r)); err != nil {
+			return nil, err
+		}
+	}
+	for i, m := range p.Machines {
+		if _, err := m.acceptedState(); err != nil {
+			return nil, fmt.Errorf("machine %d: %w", i, err)
+		}
+	}
+	return p.CurrentState(), nil
+}
+
+// DeadlockError is returned by ParallelProduct when no reachable tuple has every component final
+type DeadlockError struct {
+	States []string // States are the reachable tuples, sorted. None of them can reach a final tuple
+}
+
+func (e *DeadlockError) Error() string {
+	return fmt.Sprintf("parallel composition is deadlocked, no reachable state is final: %s", strings.Join(e.States, ", "))
+}
+
+// ParallelProduct returns the static product of the automata as a FiniteAutomaton, so composed systems can be analyzed
+// (see DeadStates) with the same tools as a single one.
+//
+//	States are the reachable tuples "(s1,s2,...)". A tuple is final if every component is final. Inputs are the events of the
+//	composition: the union of the alphabets when Synchronized, the "(index,symbol)" events when Interleaved.
+//
+//	If no tuple where every component is final can be reached, the whole system is deadlocked: a *DeadlockError listing the
+//	reachable tuples is returned instead of an FA
+func ParallelProduct(mode CompositionMode, fas ...*FiniteAutomaton) (*FiniteAutomaton, error) {
+	if len(fas) < 2 {
+		return nil, fmt.Errorf("parallel composition needs at least 2 automata, got %d", len(fas))
+	}
+	alphabets := make([]Set[string], len(fas))
+	for i, fa := range fas {
+		alphabets[i] = fa.Sigma
+	}
+	events := compositionEvents(mode, alphabets)
+	Sigma := NewSet[string]()
+	for event := range events {
+		Sigma.Add(event)
+	}
+	eventOrder := sortedStrings(Sigma)
+
+	start := make([]string, len(fas))
+	for i, fa := range fas {
+		start[i] = fa.q0
+	}
+	Q := NewSet(tupleState(start...))
+	F := NewSet[string]()
+	Delta := make(map[string]map[string]string)
+	queue := [][]string{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		name := tupleState(current...)
+
+		final := true
+		for i, fa := range fas {
+			final = final && fa.F.Contains(current[i])
+		}
+		if final {
+			F.Add(name)
+		}
+
+		Delta[name] = make(map[string]string, len(eventOrder))
+		for _, event := range eventOrder {
+			next := append([]string(nil), current...)
+			for _, r := range events[event] {
+				next[r.machine] = fas[r.machine].Delta[current[r.machine]][r.symbol]
+			}
+			nextName := tupleState(next...)
+			if !Q.Contains(nextName) {
+				Q.Add(nextName)
+				queue = append(queue, next)
+			}
+			Delta[name][event] = nextName
+		}
+	}
+	if F.Size() == 0 {
+		return nil, &DeadlockError{States: sortedStrings(Q)}
+	}
+	return NewFiniteAutomaton(Q, Sigma, tupleState(start...), F, Delta)
+}
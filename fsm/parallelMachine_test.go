@@ -0,0 +1,151 @@
+package fsm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// newPhilosopher returns a machine that takes its left fork, then its right fork, then eats and puts both back.
+// Events are named after the forks so two philosophers sharing a fork synchronize on it
+func newPhilosopher(t *testing.T, left string, right string) *FiniteAutomaton {
+	fa, err := NewFiniteAutomaton(
+		NewSet("thinking", "hasLeft", "eating"),
+		NewSet(left, right), "thinking", NewSet("thinking"),
+		map[string]map[string]string{
+			"thinking": {left: "hasLeft", right: "thinking"},
+			"hasLeft":  {left: "hasLeft", right: "eating"},
+			"eating":   {left: "eating", right: "thinking"},
+		})
+	if err != nil {
+		t.Fatalf("NewFiniteAutomaton() error = %v", err)
+	}
+	return fa
+}
+
+func TestParallelStateMachine_Synchronized(t *testing.T) {
+
+	a := newPhilosopher(t, "x", "y")
+	b := newPhilosopher(t, "y", "z")
+	p, err := NewParallelStateMachine(Synchronized, a.NewFiniteStateMachine(), b.NewFiniteStateMachine())
+	if err != nil {
+		t.Fatalf("NewParallelStateMachine() error = %v", err)
+	}
+
+	// "y" is shared, so it moves both machines at once
+	if err := p.ProcessInputRune("x"); err != nil {
+		t.Fatalf("ProcessInputRune() error = %v", err)
+	}
+	if err := p.ProcessInputRune("y"); err != nil {
+		t.Fatalf("ProcessInputRune() error = %v", err)
+	}
+	if got := p.CurrentState(); !reflect.DeepEqual(got, []string{"eating", "hasLeft"}) {
+		t.Errorf("CurrentState() = %v", got)
+	}
+	if got := p.StateName(); got != "(eating,hasLeft)" {
+		t.Errorf("StateName() = %v", got)
+	}
+	if err := p.ProcessInputRune("w"); err == nil {
+		t.Errorf("ProcessInputRune() expected error for an unknown event")
+	}
+	if _, err := p.Run("z"); err == nil {
+		t.Errorf("Run() expected error, first machine is still eating")
+	}
+}
+
+func TestParallelStateMachine_Rollback(t *testing.T) {
+
+	a := newPhilosopher(t, "x", "y")
+	b := newPhilosopher(t, "y", "z")
+	first, second := a.NewFiniteStateMachine(), b.NewFiniteStateMachine()
+	first.Tracer = NewTrace()
+	errBusy := errors.New("fork is busy")
+	second.OnExit("thinking", func(symbol string, from string, to string) error { return errBusy })
+	p, _ := NewParallelStateMachine(Synchronized, first, second)
+
+	if err := p.ProcessInputRune("y"); !errors.Is(err, errBusy) {
+		t.Fatalf("ProcessInputRune() error = %v, want %v", err, errBusy)
+	}
+	if got := p.CurrentState(); !reflect.DeepEqual(got, []string{"thinking", "thinking"}) {
+		t.Errorf("CurrentState() = %v after a veto", got)
+	}
+	// the first machine was checked but never moved
+	if first.Tracer.Len() != 0 {
+		t.Errorf("first machine trace = %v after a veto, want no step", first.Tracer.Path())
+	}
+	if err := p.ProcessInputRune("x"); err != nil || first.Tracer.Len() != 1 || first.Tracer.Steps[0].Position != 0 {
+		t.Errorf("first machine trace = %v, %v, want one step at position 0", first.Tracer.Steps, err)
+	}
+}
+
+func TestParallelStateMachine_Interleaved(t *testing.T) {
+
+	a := newPhilosopher(t, "x", "y")
+	b := newPhilosopher(t, "x", "y")
+	p, _ := NewParallelStateMachine(Interleaved, a.NewFiniteStateMachine(), b.NewFiniteStateMachine())
+	if err := p.ProcessInputRune(InterleavedSymbol(1, "x")); err != nil {
+		t.Fatalf("ProcessInputRune() error = %v", err)
+	}
+	if got := p.CurrentState(); !reflect.DeepEqual(got, []string{"thinking", "hasLeft"}) {
+		t.Errorf("CurrentState() = %v", got)
+	}
+	if err := p.ProcessInputRune("x"); err == nil {
+		t.Errorf("ProcessInputRune() expected error for an event not addressed to a machine")
+	}
+}
+
+func TestParallelProduct(t *testing.T) {
+
+	a := newPhilosopher(t, "x", "y")
+	b := newPhilosopher(t, "y", "z")
+
+	product, err := ParallelProduct(Synchronized, a, b)
+	if err != nil {
+		t.Fatalf("ParallelProduct() error = %v", err)
+	}
+	if !product.Sigma.IsSubset(NewSet("x", "y", "z")) || product.Sigma.Size() != 3 {
+		t.Errorf("ParallelProduct() Sigma = %v", product.Sigma)
+	}
+
+	// the product and the runtime agree
+	p, _ := NewParallelStateMachine(Synchronized, a.NewFiniteStateMachine(), b.NewFiniteStateMachine())
+	fsm := product.NewFiniteStateMachine()
+	for _, r := range "xyzyzxy" {
+		p.ProcessInputRune(string(r))
+		fsm.ProcessInputRune(string(r))
+		if fsm.CurrentState() != p.StateName() {
+			t.Fatalf("product state = %v, runtime state = %v", fsm.CurrentState(), p.StateName())
+		}
+	}
+
+	// every product state can get back to everyone thinking, so there is no deadlock
+	if dead := product.DeadStates(); len(dead) != 0 {
+		t.Errorf("DeadStates() = %v, want none", dead)
+	}
+
+	interleaved, err := ParallelProduct(Interleaved, a, b)
+	if err != nil {
+		t.Fatalf("ParallelProduct() error = %v", err)
+	}
+	if interleaved.Q.Size() != 9 || interleaved.Sigma.Size() != 4 {
+		t.Errorf("interleaved ParallelProduct() has %d states and %d inputs, want 9 and 4", interleaved.Q.Size(), interleaved.Sigma.Size())
+	}
+}
+
+func TestParallelProduct_Deadlock(t *testing.T) {
+
+	// both take "x" together, but a is only final before it and b only after it: no state has both final
+	a, _ := NewFiniteAutomaton(NewSet("s0", "s1"), NewSet("x"), "s0", NewSet("s1"),
+		map[string]map[string]string{"s0": {"x": "s1"}, "s1": {"x": "s1"}})
+	b, _ := NewFiniteAutomaton(NewSet("s0", "s1"), NewSet("x"), "s0", NewSet("s0"),
+		map[string]map[string]string{"s0": {"x": "s1"}, "s1": {"x": "s1"}})
+
+	product, err := ParallelProduct(Synchronized, a, b)
+	var deadlock *DeadlockError
+	if !errors.As(err, &deadlock) {
+		t.Fatalf("ParallelProduct() = %v, %v, want a *DeadlockError", product, err)
+	}
+	if !reflect.DeepEqual(deadlock.States, []string{"(s0,s0)", "(s1,s1)"}) {
+		t.Errorf("DeadlockError.States = %v, want every reachable state", deadlock.States)
+	}
+}
@@ -0,0 +1,278 @@
+package fsm
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+)
+
+// probabilityTolerance is how far from 1 the outgoing probabilities of a state can sum up to, to allow for float rounding
+const probabilityTolerance = 1e-9
+
+// ProbabilisticAutomaton is a FiniteAutomaton where each state picks its next input at random, turning it into a labelled Markov chain.
+//
+//	The outgoing transitions of every state carry probabilities summing to 1. Inputs missing from P have probability 0
+type ProbabilisticAutomaton struct {
+	FA *FiniteAutomaton              // FA gives the states, inputs and transitions, shared with the deterministic definition
+	P  map[string]map[string]float64 // P is a map between state, input and the probability of the state taking that input
+}
+
+// NewProbabilisticAutomaton creates a new ProbabilisticAutomaton from fa and the transition probabilities. Does initial error checking as well.
+func NewProbabilisticAutomaton(fa *FiniteAutomaton, P map[string]map[string]float64) (*ProbabilisticAutomaton, error) {
+	if fa == nil {
+		return nil, fmt.Errorf("FA is nil")
+	}
+	pa := ProbabilisticAutomaton{FA: fa, P: make(map[string]map[string]float64, len(P))}
+
+	// deep copy P and error check in the process
+	for state, bySymbol := range P {
+		if !fa.Q.Contains(state) {
+			return nil, fmt.Errorf("probability state %s is not one of the acceptable states", state)
+		}
+		pa.P[state] = make(map[string]float64, len(bySymbol))
+		for symbol, p := range bySymbol {
+			if !fa.Sigma.Contains(symbol) {
+				return nil, fmt.Errorf("probability input %s of state %s is not an acceptable input", symbol, state)
+			}
+			if p < 0 || p > 1 || math.IsNaN(p) {
+				return nil, fmt.Errorf("probability %v of state %s on input %s is not between 0 and 1", p, state, symbol)
+			}
+			pa.P[state][symbol] = p
+		}
+	}
+	for _, state := range sortedStrings(fa.Q) {
+		sum := 0.0
+		for _, p := range pa.P[state] {
+			sum += p
+		}
+		if math.Abs(sum-1) > probabilityTolerance {
+			return nil, fmt.Errorf("probabilities of state %s sum up to %v instead of 1", state, sum)
+		}
+	}
+	return &pa, nil
+}
+
+// String returns a string representing the ProbabilisticAutomaton as a string
+func (pa *ProbabilisticAutomaton) String() string {
+	return fmt.Sprintf("PFA: \n\tFA=%s\n\tP=%v\n", pa.FA.String(), pa.P)
+}
+
+// StringProbability returns the probability that a run from q0 starts by taking the inputs of input, in order
+func (pa *ProbabilisticAutomaton) StringProbability(input string) (float64, error) {
+	probability := 1.0
+	state := pa.FA.q0
+	for _, r := range input {
+		symbol := string(r)
+		if !pa.FA.Sigma.Contains(symbol) {
+			return 0, fmt.Errorf("rune %v is not an acceptable input", symbol)
+		}
+		probability *= pa.P[state][symbol]
+		state = pa.FA.Delta[state][symbol]
+	}
+	return probability, nil
+}
+
+// transitionMatrix returns the state order and the Markov chain matrix M, where M[i][j] is the probability of going from state i to state j
+func (pa *ProbabilisticAutomaton) transitionMatrix() ([]string, [][]float64) {
+	states := sortedStrings(pa.FA.Q)
+	index := make(map[string]int, len(states))
+	for i, state := range states {
+		index[state] = i
+	}
+	M := make([][]float64, len(states))
+	for i, state := range states {
+		M[i] = make([]float64, len(states))
+		for symbol, p := range pa.P[state] {
+			M[i][index[pa.FA.Delta[state][symbol]]] += p
+		}
+	}
+	return states, M
+}
+
+// StationaryDistribution returns the distribution π over states with πM = π, where M is the state to state Markov chain.
+//
+//	Returns an error if the chain doesn't have a unique stationary distribution (more than one closed class of states)
+func (pa *ProbabilisticAutomaton) StationaryDistribution() (map[string]float64, error) {
+	states, M := pa.transitionMatrix()
+	n := len(states)
+
+	// solve (Mᵀ - I)π = 0 with the last equation replaced by Σπ = 1
+	A := make([][]float64, n)
+	b := make([]float64, n)
+	for i := 0; i < n; i++ {
+		A[i] = make([]float64, n)
+		for j := 0; j < n; j++ {
+			A[i][j] = M[j][i]
+		}
+		A[i][i] -= 1
+	}
+	for j := 0; j < n; j++ {
+		A[n-1][j] = 1
+	}
+	b[n-1] = 1
+
+	pi, err := solveLinear(A, b)
+	if err != nil {
+		return nil, fmt.Errorf("no unique stationary distribution. Error: %v", err)
+	}
+	distribution := make(map[string]float64, n)
+	for i, state := range states {
+		distribution[state] = pi[i]
+	}
+	return distribution, nil
+}
+
+// ExpectedHittingTimes returns, for every state, the expected number of steps to reach a state of F. States of F have 0,
+// states that don't reach F with probability 1 have +Inf
+func (pa *ProbabilisticAutomaton) ExpectedHittingTimes() (map[string]float64, error) {
+	states, M := pa.transitionMatrix()
+	n := len(states)
+
+	// find the states that can reach F, walking edges with a positive probability backwards
+	canReach := make([]bool, n)
+	queue := []int{}
+	for i, state := range states {
+		if pa.FA.F.Contains(state) {
+			canReach[i] = true
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		j := queue[0]
+		queue = queue[1:]
+		for i := 0; i < n; i++ {
+			if !canReach[i] && M[i][j] > 0 {
+				canReach[i] = true
+				queue = append(queue, i)
+			}
+		}
+	}
+
+	// a state that can step, without going through F, into a state that can't reach F, misses F with a positive probability.
+	// Its expected hitting time is infinite as well
+	infinite := make([]bool, n)
+	for i := 0; i < n; i++ {
+		if !canReach[i] {
+			infinite[i] = true
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		j := queue[0]
+		queue = queue[1:]
+		for i, state := range states {
+			if !infinite[i] && !pa.FA.F.Contains(state) && M[i][j] > 0 {
+				infinite[i] = true
+				queue = append(queue, i)
+			}
+		}
+	}
+
+	// h(s) - Σ M[s][t]h(t) = 1 for the remaining states outside of F, over the unknowns of those states only
+	var unknowns []int
+	position := make(map[int]int)
+	for i, state := range states {
+		if !infinite[i] && !pa.FA.F.Contains(state) {
+			position[i] = len(unknowns)
+			unknowns = append(unknowns, i)
+		}
+	}
+	times := make(map[string]float64, n)
+	for i, state := range states {
+		switch {
+		case pa.FA.F.Contains(state):
+			times[state] = 0
+		case infinite[i]:
+			times[state] = math.Inf(1)
+		}
+	}
+	if len(unknowns) == 0 {
+		return times, nil
+	}
+
+	A := make([][]float64, len(unknowns))
+	b := make([]float64, len(unknowns))
+	for row, i := range unknowns {
+		A[row] = make([]float64, len(unknowns))
+		A[row][row] = 1
+		b[row] = 1
+		for j := 0; j < n; j++ {
+			if col, isUnknown := position[j]; isUnknown {
+				A[row][col] -= M[i][j]
+			}
+		}
+	}
+	h, err := solveLinear(A, b)
+	if err != nil {
+		return nil, fmt.Errorf("could not compute hitting times. Error: %v", err)
+	}
+	for row, i := range unknowns {
+		times[states[i]] = h[row]
+	}
+	return times, nil
+}
+
+// Sample generates a random run of at most steps inputs from q0 using rng, and returns the inputs taken and the states visited
+// (one more than the inputs). If stopAtFinal is true, the run stops as soon as it enters a state of F
+func (pa *ProbabilisticAutomaton) Sample(rng *rand.Rand, steps int, stopAtFinal bool) ([]string, []string) {
+	symbols := sortedStrings(pa.FA.Sigma) // fixed order so a seeded rng gives reproducible runs
+	state := pa.FA.q0
+	inputs := []string{}
+	visited := []string{state}
+	for step := 0; step < steps; step++ {
+		if stopAtFinal && pa.FA.F.Contains(state) {
+			break
+		}
+		draw := rng.Float64()
+		chosen := ""
+		for _, symbol := range symbols {
+			p := pa.P[state][symbol]
+			if p == 0 {
+				continue
+			}
+			chosen = symbol
+			if draw < p {
+				break
+			}
+			draw -= p
+		}
+		state = pa.FA.Delta[state][chosen]
+		inputs = append(inputs, chosen)
+		visited = append(visited, state)
+	}
+	return inputs, visited
+}
+
+// solveLinear solves Ax = b with Gaussian elimination and partial pivoting. A and b are modified
+func solveLinear(A [][]float64, b []float64) ([]float64, error) {
+	n := len(b)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(A[row][col]) > math.Abs(A[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(A[pivot][col]) < 1e-12 {
+			return nil, fmt.Errorf("linear system is singular")
+		}
+		A[col], A[pivot] = A[pivot], A[col]
+		b[col], b[pivot] = b[pivot], b[col]
+		for row := col + 1; row < n; row++ {
+			factor := A[row][col] / A[col][col]
+			for k := col; k < n; k++ {
+				A[row][k] -= factor * A[col][k]
+			}
+			b[row] -= factor * b[col]
+		}
+	}
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		sum := b[row]
+		for k := row + 1; k < n; k++ {
+			sum -= A[row][k] * x[k]
+		}
+		x[row] = sum / A[row][row]
+	}
+	return x, nil
+}
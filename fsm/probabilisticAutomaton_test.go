@@ -0,0 +1,150 @@
+package fsm
+
+import (
+	"math"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// newSessionPA returns a session flow: browse ("b") or add to cart ("c") from home, checkout ("k") from cart
+func newSessionPA(t *testing.T) *ProbabilisticAutomaton {
+	fa, _ := NewFiniteAutomaton(
+		NewSet("home", "cart", "done"),
+		NewSet("b", "c", "k"), "home", NewSet("done"),
+		map[string]map[string]string{
+			"home": {"b": "home", "c": "cart", "k": "home"},
+			"cart": {"b": "home", "c": "cart", "k": "done"},
+			"done": {"b": "home", "c": "home", "k": "home"},
+		})
+	pa, err := NewProbabilisticAutomaton(fa, map[string]map[string]float64{
+		"home": {"b": 0.5, "c": 0.5},
+		"cart": {"b": 0.5, "k": 0.5},
+		"done": {"b": 1},
+	})
+	if err != nil {
+		t.Fatalf("NewProbabilisticAutomaton() error = %v", err)
+	}
+	return pa
+}
+
+func almostEqual(a float64, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestProbabilisticAutomaton_Analysis(t *testing.T) {
+
+	pa := newSessionPA(t)
+
+	if got, _ := pa.StringProbability("bck"); !almostEqual(got, 0.125) {
+		t.Errorf("StringProbability(bck) = %v, want 0.125", got)
+	}
+	if got, _ := pa.StringProbability("k"); got != 0 {
+		t.Errorf("StringProbability(k) = %v, want 0", got)
+	}
+	if _, err := pa.StringProbability("x"); err == nil {
+		t.Errorf("StringProbability(x) expected error")
+	}
+
+	// home→home 1/2, home→cart 1/2, cart→home 1/2, cart→done 1/2, done→home 1 gives π = (4/7, 2/7, 1/7)
+	pi, err := pa.StationaryDistribution()
+	if err != nil {
+		t.Fatalf("StationaryDistribution() error = %v", err)
+	}
+	want := map[string]float64{"home": 4.0 / 7, "cart": 2.0 / 7, "done": 1.0 / 7}
+	for state, p := range want {
+		if !almostEqual(pi[state], p) {
+			t.Errorf("StationaryDistribution()[%s] = %v, want %v", state, pi[state], p)
+		}
+	}
+
+	// h(home) = 1 + h(home)/2 + h(cart)/2, h(cart) = 1 + h(home)/2 gives h(home) = 6, h(cart) = 4
+	times, err := pa.ExpectedHittingTimes()
+	if err != nil {
+		t.Fatalf("ExpectedHittingTimes() error = %v", err)
+	}
+	wantTimes := map[string]float64{"home": 6, "cart": 4, "done": 0}
+	for state, h := range wantTimes {
+		if !almostEqual(times[state], h) {
+			t.Errorf("ExpectedHittingTimes()[%s] = %v, want %v", state, times[state], h)
+		}
+	}
+}
+
+func TestProbabilisticAutomaton_UnreachableFinalState(t *testing.T) {
+
+	fa, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("a", "b"), "S0", NewSet("S2"),
+		map[string]map[string]string{
+			"S0": {"a": "S1", "b": "S2"},
+			"S1": {"a": "S1", "b": "S1"},
+			"S2": {"a": "S2", "b": "S2"},
+		})
+	pa, _ := NewProbabilisticAutomaton(fa, map[string]map[string]float64{
+		"S0": {"a": 0.5, "b": 0.5}, "S1": {"a": 1}, "S2": {"b": 1},
+	})
+	times, err := pa.ExpectedHittingTimes()
+	if err != nil {
+		t.Fatalf("ExpectedHittingTimes() error = %v", err)
+	}
+	// S0 reaches F only half of the time, so its expected time is infinite too
+	if !math.IsInf(times["S0"], 1) || !math.IsInf(times["S1"], 1) || times["S2"] != 0 {
+		t.Errorf("ExpectedHittingTimes() = %v, want S0=+Inf, S1=+Inf, S2=0", times)
+	}
+
+	// S1 and S2 are two closed classes
+	if _, err := pa.StationaryDistribution(); err == nil {
+		t.Errorf("StationaryDistribution() expected error for a chain with two closed classes")
+	}
+}
+
+func TestProbabilisticAutomaton_Sample(t *testing.T) {
+
+	pa := newSessionPA(t)
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 100; i++ {
+		inputs, visited := pa.Sample(rng, 50, true)
+		if len(visited) != len(inputs)+1 {
+			t.Fatalf("Sample() visited %d states for %d inputs", len(visited), len(inputs))
+		}
+		// every sampled run must have a positive probability and follow Delta
+		p, err := pa.StringProbability(strings.Join(inputs, ""))
+		if err != nil || p == 0 {
+			t.Errorf("Sample() = %v has probability %v, %v", inputs, p, err)
+		}
+		if len(inputs) < 50 && visited[len(visited)-1] != "done" {
+			t.Errorf("Sample() stopped early in %v", visited[len(visited)-1])
+		}
+	}
+}
+
+func TestNewProbabilisticAutomaton(t *testing.T) {
+
+	fa, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1"),
+		NewSet("a", "b"), "S0", NewSet("S1"),
+		map[string]map[string]string{
+			"S0": {"a": "S0", "b": "S1"},
+			"S1": {"a": "S0", "b": "S1"},
+		})
+	tests := []struct {
+		name    string
+		P       map[string]map[string]float64
+		wantErr bool
+	}{
+		{name: "valid", P: map[string]map[string]float64{"S0": {"a": 0.3, "b": 0.7}, "S1": {"a": 1}}},
+		{name: "does not sum to 1", P: map[string]map[string]float64{"S0": {"a": 0.3, "b": 0.6}, "S1": {"a": 1}}, wantErr: true},
+		{name: "missing state", P: map[string]map[string]float64{"S0": {"a": 0.3, "b": 0.7}}, wantErr: true},
+		{name: "negative", P: map[string]map[string]float64{"S0": {"a": -0.5, "b": 1.5}, "S1": {"a": 1}}, wantErr: true},
+		{name: "unknown input", P: map[string]map[string]float64{"S0": {"c": 1}, "S1": {"a": 1}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewProbabilisticAutomaton(fa, tt.P)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewProbabilisticAutomaton() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Epsilon is the empty input of a PushdownAutomaton transition, taken without consuming a rune
+const Epsilon = ""
+
+// PDAAcceptance selects how a PushdownAutomaton accepts its input
+type PDAAcceptance int
+
+const (
+	AcceptByFinalState PDAAcceptance = iota // AcceptByFinalState accepts when the input is consumed in a state of F
+	AcceptByEmptyStack                      // AcceptByEmptyStack accepts when the input is consumed with an empty stack
+)
+
+// PDATransition is a transition of a PushdownAutomaton: in state From, reading Input (or Epsilon) with Pop on top of the stack,
+// go to state To and replace Pop by Push. Push is written top first, an empty Push just pops
+type PDATransition struct {
+	From  string
+	Input string
+	Pop   string
+	To    string
+	Push  []string
+}
+
+// PushdownAutomaton represents a (possibly nondeterministic) Pushdown Automaton with the tuple (Q,Σ,Γ,δ,q0,Z0,F)
+type PushdownAutomaton struct {
+	Q           Set[string]     // Q is the set of acceptable PDA states
+	Sigma       Set[string]     // Sigma is the acceptable set of inputs
+	Gamma       Set[string]     // Gamma is the stack alphabet
+	Delta       []PDATransition // Delta is the list of transitions
+	q0          string          // q0 is the initial state
+	Z0          string          // Z0 is the initial stack symbol
+	F           Set[string]     // F is the set of final states. Can be empty when accepting by empty stack
+	Acceptance  PDAAcceptance   // Acceptance selects acceptance by final state or by empty stack
+	MaxSteps    int             // MaxSteps bounds the number of configurations explored by Accepts, to cut infinite ε-loops. DefaultPDAMaxSteps if 0
+	transitions map[string][]PDATransition
+}
+
+// DefaultPDAMaxSteps is the number of configurations Accepts explores when MaxSteps is not set
+const DefaultPDAMaxSteps = 100000
+
+// NewPushdownAutomaton creates a new PDA with the tuple (Q,Σ,Γ,δ,q0,Z0,F). Does initial error checking as well.
+func NewPushdownAutomaton(Q Set[string], Sigma Set[string], Gamma Set[string], Delta []PDATransition, q0 string, Z0 string, F Set[string], acceptance PDAAcceptance) (*PushdownAutomaton, error) {
+	if len(Q) == 0 {
+		return nil, fmt.Errorf("Q(list of acceptable states) is empty")
+	}
+	if len(Sigma) == 0 {
+		return nil, fmt.Errorf("Σ(Sigma)=(list of acceptable input) is empty")
+	}
+	if Sigma.Contains(Epsilon) {
+		return nil, fmt.Errorf("Σ(Sigma) can't contain the empty input")
+	}
+	if len(Gamma) == 0 {
+		return nil, fmt.Errorf("Γ(Gamma)=(stack alphabet) is empty")
+	}
+	if !Q.Contains(q0) {
+		return nil, fmt.Errorf("q0(initial state) is not one of the acceptable states")
+	}
+	if !Gamma.Contains(Z0) {
+		return nil, fmt.Errorf("Z0(initial stack symbol) is not in the stack alphabet")
+	}
+	if acceptance == AcceptByFinalState && len(F) == 0 {
+		return nil, fmt.Errorf("F(list of acceptable final states) is empty")
+	}
+	if !F.IsSubset(Q) {
+		return nil, fmt.Errorf("F(list of acceptable final states) is not a subset of Q")
+	}
+
+	pda := PushdownAutomaton{
+		Q: Q.DeepCopy(), Sigma: Sigma.DeepCopy(), Gamma: Gamma.DeepCopy(), q0: q0, Z0: Z0, F: F.DeepCopy(),
+		Acceptance: acceptance, transitions: make(map[string][]PDATransition),
+	}
+	for _, t := range Delta {
+		if !Q.Contains(t.From) || !Q.Contains(t.To) {
+			return nil, fmt.Errorf("transition %v uses a state that is not one of the acceptable states", t)
+		}
+		if t.Input != Epsilon && !Sigma.Contains(t.Input) {
+			return nil, fmt.Errorf("transition %v uses an input that is not an acceptable input", t)
+		}
+		if !Gamma.Contains(t.Pop) {
+			return nil, fmt.Errorf("transition %v pops a symbol that is not in the stack alphabet", t)
+		}
+		for _, symbol := range t.Push {
+			if !Gamma.Contains(symbol) {
+				return nil, fmt.Errorf("transition %v pushes a symbol that is not in the stack alphabet", t)
+			}
+		}
+		copied := t
+		copied.Push = append([]string(nil), t.Push...)
+		pda.Delta = append(pda.Delta, copied)
+		pda.transitions[t.From] = append(pda.transitions[t.From], copied)
+	}
+	return &pda, nil
+}
+
+// String returns a string representing the PushdownAutomaton as a string
+func (p *PushdownAutomaton) String() string {
+	return fmt.Sprintf("PDA:\n\tQ=%s\n\tΣ=%s\n\tΓ=%s\n\tq0=%v\n\tZ0=%v\n\tF=%s\n\tδ=%v\n", p.Q.String(), p.Sigma.String(), p.Gamma.String(), p.q0, p.Z0, p.F.String(), p.Delta)
+}
+
+// pdaConfiguration is an instantaneous description of a PDA run: state, position in the input and stack (top last)
+type pdaConfiguration struct {
+	state    string
+	position int
+	stack    []string
+}
+
+// key returns a string identifying the configuration, to avoid exploring it twice
+func (c pdaConfiguration) key() string {
+	return fmt.Sprintf("%s|%d|%s", c.state, c.position, strings.Join(c.stack, "\x00"))
+}
+
+// Accepts reports whether input is accepted, exploring the nondeterministic choices breadth first.
+//
+//	Returns an error if input has a rune outside of Sigma, or if MaxSteps configurations were explored without reaching a decision
+func (p *PushdownAutomaton) Accepts(input string) (bool, error) {
+	var runes []string
+	for _, r := range input {
+		if !p.Sigma.Contains(string(r)) {
+			return false, fmt.Errorf("rune %v is not an acceptable input", string(r))
+		}
+		runes = append(runes, string(r))
+	}
+	maxSteps := p.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = DefaultPDAMaxSteps
+	}
+
+	start := pdaConfiguration{state: p.q0, stack: []string{p.Z0}}
+	queue := []pdaConfiguration{start}
+	seen := NewSet(start.key())
+	for steps := 0; len(queue) > 0; steps++ {
+		if steps >= maxSteps {
+			return false, fmt.Errorf("no decision after exploring %d configurations", maxSteps)
+		}
+		current := queue[0]
+		queue = queue[1:]
+		if current.position == len(runes) && p.accepting(current) {
+			return true, nil
+		}
+		if len(current.stack) == 0 {
+			continue // no transition can fire on an empty stack
+		}
+		top := current.stack[len(current.stack)-1]
+		for _, t := range p.transitions[current.state] {
+			if t.Pop != top {
+				continue
+			}
+			position := current.position
+			if t.Input != Epsilon {
+				if position == len(runes) || runes[position] != t.Input {
+					continue
+				}
+				position++
+			}
+			stack := make([]string, 0, len(current.stack)-1+len(t.Push))
+			stack = append(stack, current.stack[:len(current.stack)-1]...)
+			for i := len(t.Push) - 1; i >= 0; i-- { // Push is top first, so push it in reverse
+				stack = append(stack, t.Push[i])
+			}
+			next := pdaConfiguration{state: t.To, position: position, stack: stack}
+			if !seen.Contains(next.key()) {
+				seen.Add(next.key())
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false, nil
+}
+
+// accepting reports whether a configuration that consumed all the input is accepting
+func (p *PushdownAutomaton) accepting(c pdaConfiguration) bool {
+	if p.Acceptance == AcceptByEmptyStack {
+		return len(c.stack) == 0
+	}
+	return p.F.Contains(c.state)
+}
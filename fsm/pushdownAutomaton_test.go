@@ -0,0 +1,127 @@
+package fsm
+
+import (
+	"testing"
+)
+
+// newBracketsPDA returns a PDA accepting balanced "(" ")" and "[" "]" brackets
+func newBracketsPDA(t *testing.T, acceptance PDAAcceptance) *PushdownAutomaton {
+	pda, err := NewPushdownAutomaton(
+		NewSet("S0", "S1"),
+		NewSet("(", ")", "[", "]"),
+		NewSet("Z", "P", "B"),
+		[]PDATransition{
+			{From: "S0", Input: "(", Pop: "Z", To: "S0", Push: []string{"P", "Z"}},
+			{From: "S0", Input: "(", Pop: "P", To: "S0", Push: []string{"P", "P"}},
+			{From: "S0", Input: "(", Pop: "B", To: "S0", Push: []string{"P", "B"}},
+			{From: "S0", Input: "[", Pop: "Z", To: "S0", Push: []string{"B", "Z"}},
+			{From: "S0", Input: "[", Pop: "P", To: "S0", Push: []string{"B", "P"}},
+			{From: "S0", Input: "[", Pop: "B", To: "S0", Push: []string{"B", "B"}},
+			{From: "S0", Input: ")", Pop: "P", To: "S0"},
+			{From: "S0", Input: "]", Pop: "B", To: "S0"},
+			{From: "S0", Input: Epsilon, Pop: "Z", To: "S1"}, // bottom of the stack reached, done
+		},
+		"S0", "Z", NewSet("S1"), acceptance)
+	if err != nil {
+		t.Fatalf("NewPushdownAutomaton() error = %v", err)
+	}
+	return pda
+}
+
+func TestPushdownAutomaton_Accepts(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		input   string
+		want    bool
+		wantErr bool
+	}{
+		{name: "empty", input: "", want: true},
+		{name: "nested", input: "([()[]])", want: true},
+		{name: "sequence", input: "()[]()", want: true},
+		{name: "unclosed", input: "(()", want: false},
+		{name: "crossed", input: "([)]", want: false},
+		{name: "bad input", input: "(a)", wantErr: true},
+	}
+	for _, acceptance := range []PDAAcceptance{AcceptByFinalState, AcceptByEmptyStack} {
+		pda := newBracketsPDA(t, acceptance)
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := pda.Accepts(tt.input)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("PushdownAutomaton.Accepts() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if got != tt.want {
+					t.Errorf("PushdownAutomaton.Accepts(%s) = %v, want %v (acceptance %v)", tt.input, got, tt.want, acceptance)
+				}
+			})
+		}
+	}
+}
+
+func TestPushdownAutomaton_Nondeterministic(t *testing.T) {
+
+	// even length palindromes over (a, b): guess the middle with an ε transition
+	var delta []PDATransition
+	for _, input := range []string{"a", "b"} {
+		for _, top := range []string{"Z", "a", "b"} {
+			delta = append(delta, PDATransition{From: "push", Input: input, Pop: top, To: "push", Push: []string{input, top}})
+		}
+		delta = append(delta, PDATransition{From: "pop", Input: input, Pop: input, To: "pop"})
+	}
+	for _, top := range []string{"Z", "a", "b"} {
+		delta = append(delta, PDATransition{From: "push", Input: Epsilon, Pop: top, To: "pop", Push: []string{top}})
+	}
+	delta = append(delta, PDATransition{From: "pop", Input: Epsilon, Pop: "Z", To: "done", Push: []string{"Z"}})
+
+	pda, err := NewPushdownAutomaton(NewSet("push", "pop", "done"), NewSet("a", "b"), NewSet("Z", "a", "b"), delta, "push", "Z", NewSet("done"), AcceptByFinalState)
+	if err != nil {
+		t.Fatalf("NewPushdownAutomaton() error = %v", err)
+	}
+	for input, want := range map[string]bool{"": true, "abba": true, "abab": false, "aabbaa": true, "aba": false} {
+		if got, err := pda.Accepts(input); err != nil || got != want {
+			t.Errorf("PushdownAutomaton.Accepts(%s) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+}
+
+func TestPushdownAutomaton_MaxSteps(t *testing.T) {
+
+	// an ε-loop that keeps growing the stack never reaches a decision
+	pda, _ := NewPushdownAutomaton(NewSet("S0", "S1"), NewSet("a"), NewSet("Z"),
+		[]PDATransition{{From: "S0", Input: Epsilon, Pop: "Z", To: "S0", Push: []string{"Z", "Z"}}},
+		"S0", "Z", NewSet("S1"), AcceptByFinalState)
+	pda.MaxSteps = 50
+	if _, err := pda.Accepts("a"); err == nil {
+		t.Errorf("PushdownAutomaton.Accepts() expected error for an unbounded ε-loop")
+	}
+}
+
+func TestNewPushdownAutomaton(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		Q       Set[string]
+		Gamma   Set[string]
+		Delta   []PDATransition
+		q0      string
+		Z0      string
+		F       Set[string]
+		wantErr bool
+	}{
+		{name: "valid", Q: NewSet("S0"), Gamma: NewSet("Z"), Delta: []PDATransition{{From: "S0", Input: "a", Pop: "Z", To: "S0"}}, q0: "S0", Z0: "Z", F: NewSet("S0")},
+		{name: "bad q0", Q: NewSet("S0"), Gamma: NewSet("Z"), q0: "S1", Z0: "Z", F: NewSet("S0"), wantErr: true},
+		{name: "bad Z0", Q: NewSet("S0"), Gamma: NewSet("Z"), q0: "S0", Z0: "Y", F: NewSet("S0"), wantErr: true},
+		{name: "empty F", Q: NewSet("S0"), Gamma: NewSet("Z"), q0: "S0", Z0: "Z", F: NewSet[string](), wantErr: true},
+		{name: "bad push", Q: NewSet("S0"), Gamma: NewSet("Z"), Delta: []PDATransition{{From: "S0", Input: "a", Pop: "Z", To: "S0", Push: []string{"Y"}}}, q0: "S0", Z0: "Z", F: NewSet("S0"), wantErr: true},
+		{name: "bad input", Q: NewSet("S0"), Gamma: NewSet("Z"), Delta: []PDATransition{{From: "S0", Input: "b", Pop: "Z", To: "S0"}}, q0: "S0", Z0: "Z", F: NewSet("S0"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPushdownAutomaton(tt.Q, NewSet("a"), tt.Gamma, tt.Delta, tt.q0, tt.Z0, tt.F, AcceptByFinalState)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewPushdownAutomaton() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
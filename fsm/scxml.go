@@ -0,0 +1,238 @@
+package fsm
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+const (
+	scxmlNamespace = "http://www.w3.org/2005/07/scxml"               // scxmlNamespace is the namespace of SCXML elements
+	faNamespace    = "https://github.com/nabbas-ca/finite-automaton" // faNamespace is the namespace of the fa:accepting attribute
+)
+
+// scxmlNode is any element of an SCXML document, read generically so unsupported constructs can be reported by name
+type scxmlNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr  `xml:",any,attr"`
+	Children []scxmlNode `xml:",any"`
+}
+
+// attr returns the value of the attribute local in namespace space ("" for no namespace), and whether it exists
+func (n scxmlNode) attr(space, local string) (string, bool) {
+	for _, a := range n.Attrs {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// ReadSCXML reads a flat SCXML state chart: states and final states directly under <scxml>, whose transitions have an event
+// and a target. Events make Sigma.
+//
+//	An event a state has no transition for is ignored by SCXML, so it becomes a self loop. <final> states and states with
+//	fa:accepting="true" (see WriteSCXML) are the final states of the FA. Nested and parallel states, <datamodel>, <script>,
+//	executable content, conditions, eventless transitions and wildcard events have no FiniteAutomaton equivalent and are errors
+func ReadSCXML(r io.Reader) (*FiniteAutomaton, error) {
+	var root scxmlNode
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("could not parse SCXML document. Error: %v", err)
+	}
+	if root.XMLName.Local != "scxml" || root.XMLName.Space != scxmlNamespace && root.XMLName.Space != "" {
+		return nil, fmt.Errorf("root element is <%s>, expected <scxml>", root.XMLName.Local)
+	}
+
+	Q, F := NewSet[string](), NewSet[string]()
+	var order []string                                // states in document order
+	transitions := make(map[string]map[string]string) // state, event, target in the document
+	for _, child := range root.Children {
+		id, _ := child.attr("", "id")
+		switch child.XMLName.Local {
+		case "state", "final":
+			if id == "" {
+				return nil, fmt.Errorf("<%s> without id is not supported", child.XMLName.Local)
+			}
+			if Q.Contains(id) {
+				return nil, fmt.Errorf("state %s is defined twice", id)
+			}
+			Q.Add(id)
+			order = append(order, id)
+			transitions[id] = make(map[string]string)
+			if accepting, _ := child.attr(faNamespace, "accepting"); child.XMLName.Local == "final" || accepting == "true" {
+				F.Add(id)
+			}
+		case "datamodel", "script":
+			return nil, fmt.Errorf("<%s> is not supported, a FiniteAutomaton has no data", child.XMLName.Local)
+		case "parallel":
+			return nil, fmt.Errorf("<parallel> %s is not supported, only flat state charts can be read", id)
+		default:
+			return nil, fmt.Errorf("<%s> is not supported", child.XMLName.Local)
+		}
+
+		for _, grandchild := range child.Children {
+			switch grandchild.XMLName.Local {
+			case "transition":
+				if child.XMLName.Local == "final" {
+					return nil, fmt.Errorf("final state %s has a transition, SCXML final states can't be left", id)
+				}
+				if err := readSCXMLTransition(id, grandchild, transitions[id]); err != nil {
+					return nil, err
+				}
+			case "state", "parallel", "final", "initial", "history":
+				return nil, fmt.Errorf("state %s has nested <%s>, only flat state charts can be read", id, grandchild.XMLName.Local)
+			case "datamodel", "script":
+				return nil, fmt.Errorf("state %s has <%s>, which is not supported, a FiniteAutomaton has no data", id, grandchild.XMLName.Local)
+			default:
+				return nil, fmt.Errorf("state %s has <%s>, which is not supported", id, grandchild.XMLName.Local)
+			}
+		}
+	}
+	if len(order) == 0 {
+		return nil, fmt.Errorf("SCXML document has no state")
+	}
+
+	q0, exists := root.attr("", "initial")
+	if !exists {
+		q0 = order[0] // the first state in document order
+	}
+	if !Q.Contains(q0) {
+		return nil, fmt.Errorf("initial state %s is not one of the states", q0)
+	}
+
+	Sigma := NewSet[string]()
+	for _, state := range order {
+		for event, target := range transitions[state] {
+			if !Q.Contains(target) {
+				return nil, fmt.Errorf("transition of state %s on event %s goes to %s, which is not one of the states", state, event, target)
+			}
+			Sigma.Add(event)
+		}
+	}
+	if len(Sigma) == 0 {
+		return nil, fmt.Errorf("SCXML document has no transition, so no events")
+	}
+	// SCXML events are matched by prefix: a transition on "a" also takes "a.b"
+	for _, event := range sortedStrings(Sigma) {
+		for _, other := range sortedStrings(Sigma) {
+			if strings.HasPrefix(other, event+".") {
+				return nil, fmt.Errorf("event %s would also match %s, SCXML prefix matching is not supported", event, other)
+			}
+		}
+	}
+
+	delta := make(map[string]map[string]string, len(order))
+	for _, state := range order {
+		delta[state] = make(map[string]string, len(Sigma))
+		for event := range Sigma {
+			delta[state][event] = state // ignored events leave the state unchanged
+			if target, exists := transitions[state][event]; exists {
+				delta[state][event] = target
+			}
+		}
+	}
+	return NewFiniteAutomaton(Q, Sigma, q0, F, delta)
+}
+
+// readSCXMLTransition adds the transition of state to transitions, one entry per event of its event attribute
+func readSCXMLTransition(state string, transition scxmlNode, transitions map[string]string) error {
+	if len(transition.Children) > 0 {
+		return fmt.Errorf("transition of state %s has executable content <%s>, which is not supported", state, transition.Children[0].XMLName.Local)
+	}
+	if _, exists := transition.attr("", "cond"); exists {
+		return fmt.Errorf("transition of state %s has a cond, which is not supported, a FiniteAutomaton has no data", state)
+	}
+	event, _ := transition.attr("", "event")
+	events := strings.Fields(event)
+	if len(events) == 0 {
+		return fmt.Errorf("eventless transition of state %s is not supported", state)
+	}
+	target, exists := transition.attr("", "target")
+	if !exists {
+		target = state // targetless transitions don't change the state
+	}
+	if len(strings.Fields(target)) != 1 {
+		return fmt.Errorf("transition of state %s has target %q, exactly one target is supported", state, target)
+	}
+	for _, e := range events {
+		if e == "*" || strings.HasSuffix(e, ".*") {
+			return fmt.Errorf("transition of state %s has wildcard event %s, which is not supported", state, e)
+		}
+		e = strings.TrimSuffix(e, ".")
+		if previous, exists := transitions[e]; exists {
+			return fmt.Errorf("state %s has two transitions on event %s, to %s and %s", state, e, previous, target)
+		}
+		transitions[e] = target
+	}
+	return nil
+}
+
+// scxmlEscape escapes text for XML attributes
+var scxmlEscape = strings.NewReplacer(`&`, `&amp;`, `<`, `&lt;`, `>`, `&gt;`, `"`, `&quot;`)
+
+// WriteSCXML writes the FA as a flat SCXML state chart that ReadSCXML reads back. States are sorted, with one transition per
+// event, sorted. Self loops are left out, since SCXML ignores events a state has no transition for. An input that only has
+// self loops is kept as a targetless transition of q0.
+//
+//	A final state whose transitions are all self loops is a <final>. Other final states can't be <final>, which ends the
+//	state chart, so they are marked with fa:accepting="true", an attribute other SCXML processors ignore. Inputs with
+//	whitespace can't be written, since event lists are space separated, nor can empty states or states with whitespace, since
+//	targets are space separated too
+func (f *FiniteAutomaton) WriteSCXML(w io.Writer) error {
+	for _, state := range sortedStrings(f.Q) {
+		if state == "" || strings.IndexFunc(state, unicode.IsSpace) >= 0 {
+			return fmt.Errorf("state %q is empty or contains whitespace, it can't be an SCXML id", state)
+		}
+	}
+	events := sortedStrings(f.Sigma)
+	for _, event := range events {
+		if strings.IndexFunc(event, unicode.IsSpace) >= 0 || event == "*" || strings.HasSuffix(event, ".*") || strings.HasSuffix(event, ".") {
+			return fmt.Errorf("input %q can't be an SCXML event", event)
+		}
+		for _, other := range events {
+			if strings.HasPrefix(other, event+".") {
+				return fmt.Errorf("input %s would also match %s in SCXML", event, other)
+			}
+		}
+	}
+	// events that only ever loop would be lost, they get a targetless transition on q0
+	looping := NewSet(events...)
+	for _, state := range sortedStrings(f.Q) {
+		for _, event := range events {
+			if f.Delta[state][event] != state {
+				looping.Remove(event)
+			}
+		}
+	}
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&b, `<scxml xmlns="%s" xmlns:fa="%s" version="1.0" initial="%s">`+"\n", scxmlNamespace, faNamespace, scxmlEscape.Replace(f.q0))
+	for _, state := range sortedStrings(f.Q) {
+		var lines []string
+		for _, event := range events {
+			if to := f.Delta[state][event]; to != state {
+				lines = append(lines, fmt.Sprintf(`		<transition event="%s" target="%s"/>`, scxmlEscape.Replace(event), scxmlEscape.Replace(to)))
+			} else if state == f.q0 && looping.Contains(event) {
+				lines = append(lines, fmt.Sprintf(`		<transition event="%s"/>`, scxmlEscape.Replace(event)))
+			}
+		}
+		id := scxmlEscape.Replace(state)
+		switch {
+		case f.F.Contains(state) && len(lines) == 0:
+			fmt.Fprintf(&b, "\t<final id=\"%s\"/>\n", id)
+		case len(lines) == 0:
+			fmt.Fprintf(&b, "\t<state id=\"%s\"/>\n", id)
+		default:
+			accepting := ""
+			if f.F.Contains(state) {
+				accepting = ` fa:accepting="true"`
+			}
+			fmt.Fprintf(&b, "\t<state id=\"%s\"%s>\n%s\n\t</state>\n", id, accepting, strings.Join(lines, "\n"))
+		}
+	}
+	b.WriteString("</scxml>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
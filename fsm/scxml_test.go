@@ -0,0 +1,168 @@
+package fsm
+
+import (
+	"strings"
+	"testing"
+)
+
+// doorSCXML is a flat state chart of a door, written the way WriteSCXML writes it
+const doorSCXML = `<?xml version="1.0" encoding="UTF-8"?>
+<scxml xmlns="http://www.w3.org/2005/07/scxml" xmlns:fa="https://github.com/nabbas-ca/finite-automaton" version="1.0" initial="closed">
+	<state id="closed" fa:accepting="true">
+		<transition event="lock" target="locked"/>
+		<transition event="open" target="opened"/>
+	</state>
+	<final id="gone"/>
+	<state id="locked">
+		<transition event="break" target="gone"/>
+		<transition event="unlock" target="closed"/>
+	</state>
+	<state id="opened">
+		<transition event="break" target="gone"/>
+		<transition event="close" target="closed"/>
+	</state>
+</scxml>
+`
+
+func TestReadSCXML(t *testing.T) {
+
+	events := NewSet("open", "close", "lock", "unlock", "break")
+	delta := make(map[string]map[string]string)
+	for _, state := range []string{"closed", "opened", "locked", "gone"} {
+		delta[state] = make(map[string]string)
+		for event := range events {
+			delta[state][event] = state // ignored events
+		}
+	}
+	delta["closed"]["open"], delta["closed"]["lock"] = "opened", "locked"
+	delta["opened"]["close"], delta["opened"]["break"] = "closed", "gone"
+	delta["locked"]["unlock"], delta["locked"]["break"] = "closed", "gone"
+	doorFA, _ := NewFiniteAutomaton(NewSet("closed", "opened", "locked", "gone"), events, "closed", NewSet("closed", "gone"), delta)
+
+	fa, err := ReadSCXML(strings.NewReader(doorSCXML))
+	if err != nil {
+		t.Fatalf("ReadSCXML() error = %v", err)
+	}
+	if !fa.Equals(doorFA) {
+		t.Errorf("ReadSCXML() = %v, want %v", fa, doorFA)
+	}
+
+	// a flat SCXML file round trips unchanged
+	var b strings.Builder
+	if err := fa.WriteSCXML(&b); err != nil {
+		t.Fatalf("WriteSCXML() error = %v", err)
+	}
+	if b.String() != doorSCXML {
+		t.Errorf("WriteSCXML() = \n%s\n want \n%s", b.String(), doorSCXML)
+	}
+
+	// hand written: no initial attribute, event lists, targetless transitions, comments and no namespace
+	handWritten := `<scxml version="1.0">
+		<!-- toggles on every press -->
+		<state id="off"><transition event="press hold" target="on"/><transition event="tap"/></state>
+		<final id="on"/>
+	</scxml>`
+	fa, err = ReadSCXML(strings.NewReader(handWritten))
+	if err != nil {
+		t.Fatalf("ReadSCXML() error = %v", err)
+	}
+	toggleFA, _ := NewFiniteAutomaton(NewSet("off", "on"), NewSet("press", "hold", "tap"), "off", NewSet("on"),
+		map[string]map[string]string{
+			"off": {"press": "on", "hold": "on", "tap": "off"},
+			"on":  {"press": "on", "hold": "on", "tap": "on"},
+		})
+	if !fa.Equals(toggleFA) {
+		t.Errorf("ReadSCXML() = %v, want %v", fa, toggleFA)
+	}
+}
+
+func TestFiniteAutomaton_WriteSCXML(t *testing.T) {
+
+	// input x only loops, q0 is final and can't be a <final>
+	loopFA, _ := NewFiniteAutomaton(NewSet("S0", "S1"), NewSet("a", "x"), "S0", NewSet("S0"),
+		map[string]map[string]string{
+			"S0": {"a": "S1", "x": "S0"},
+			"S1": {"a": "S0", "x": "S1"},
+		})
+	var b strings.Builder
+	if err := loopFA.WriteSCXML(&b); err != nil {
+		t.Fatalf("WriteSCXML() error = %v", err)
+	}
+	if !strings.Contains(b.String(), `<transition event="x"/>`) || !strings.Contains(b.String(), `<state id="S0" fa:accepting="true">`) {
+		t.Errorf("WriteSCXML() = \n%s", b.String())
+	}
+	fa, err := ReadSCXML(strings.NewReader(b.String()))
+	if err != nil || !fa.Equals(loopFA) {
+		t.Errorf("ReadSCXML() of WriteSCXML() = %v, %v, want %v", fa, err, loopFA)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "space", input: "a b"},
+		{name: "wildcard", input: "*"},
+		{name: "prefix", input: "x.y"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fa, _ := NewFiniteAutomaton(NewSet("S0"), NewSet(tt.input, "x"), "S0", NewSet("S0"), map[string]map[string]string{"S0": {tt.input: "S0", "x": "S0"}})
+			if err := fa.WriteSCXML(&b); err == nil {
+				t.Errorf("WriteSCXML() expected error for input %q", tt.input)
+			}
+		})
+	}
+
+	// a target "s 1" would read back as two targets
+	for _, state := range []string{"s 1", ""} {
+		fa, _ := NewFiniteAutomaton(NewSet("S0", state), NewSet("a"), "S0", NewSet("S0"),
+			map[string]map[string]string{"S0": {"a": state}, state: {"a": "S0"}})
+		if err := fa.WriteSCXML(&b); err == nil || !strings.Contains(err.Error(), "can't be an SCXML id") {
+			t.Errorf("WriteSCXML() error = %v, want an error for state %q", err, state)
+		}
+	}
+}
+
+func TestReadSCXML_Errors(t *testing.T) {
+
+	// chart wraps states in an scxml element
+	chart := func(states string) string {
+		return `<scxml xmlns="http://www.w3.org/2005/07/scxml" version="1.0">` + states + `</scxml>`
+	}
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "not xml", data: "<scxml>", wantErr: "could not parse SCXML document"},
+		{name: "not scxml", data: "<structure/>", wantErr: "root element is <structure>"},
+		{name: "datamodel", data: chart(`<datamodel><data id="x"/></datamodel><state id="a"/>`), wantErr: "<datamodel> is not supported"},
+		{name: "script", data: chart(`<script>x = 1</script>`), wantErr: "<script> is not supported"},
+		{name: "state datamodel", data: chart(`<state id="a"><datamodel/></state>`), wantErr: "state a has <datamodel>"},
+		{name: "parallel", data: chart(`<parallel id="p"/>`), wantErr: "<parallel> p is not supported"},
+		{name: "nested", data: chart(`<state id="a"><state id="b"/></state>`), wantErr: "state a has nested <state>"},
+		{name: "onentry", data: chart(`<state id="a"><onentry/></state>`), wantErr: "state a has <onentry>"},
+		{name: "executable content", data: chart(`<state id="a"><transition event="e" target="a"><log expr="1"/></transition></state>`), wantErr: "executable content <log>"},
+		{name: "cond", data: chart(`<state id="a"><transition event="e" cond="x" target="a"/></state>`), wantErr: "has a cond"},
+		{name: "eventless", data: chart(`<state id="a"><transition target="a"/></state>`), wantErr: "eventless transition of state a"},
+		{name: "wildcard", data: chart(`<state id="a"><transition event="*" target="a"/></state>`), wantErr: "wildcard event *"},
+		{name: "two targets", data: chart(`<state id="a"><transition event="e" target="a b"/></state><state id="b"/>`), wantErr: "exactly one target"},
+		{name: "duplicate event", data: chart(`<state id="a"><transition event="e" target="a"/><transition event="e" target="b"/></state><state id="b"/>`), wantErr: "state a has two transitions on event e"},
+		{name: "unknown target", data: chart(`<state id="a"><transition event="e" target="z"/></state>`), wantErr: "goes to z"},
+		{name: "prefix", data: chart(`<state id="a"><transition event="e" target="a"/><transition event="e.x" target="a"/></state>`), wantErr: "event e would also match e.x"},
+		{name: "final with transition", data: chart(`<final id="a"><transition event="e" target="a"/></final>`), wantErr: "final state a has a transition"},
+		{name: "duplicate state", data: chart(`<state id="a"/><state id="a"/>`), wantErr: "state a is defined twice"},
+		{name: "no state", data: chart(``), wantErr: "no state"},
+		{name: "no events", data: chart(`<state id="a"/>`), wantErr: "no transition"},
+		{name: "bad initial", data: `<scxml initial="z"><state id="a"/></scxml>`, wantErr: "initial state z"},
+		{name: "no final state", data: chart(`<state id="a"><transition event="e" target="a"/></state>`), wantErr: "F(list of acceptable final states) is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadSCXML(strings.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadSCXML() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
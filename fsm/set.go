@@ -39,18 +39,18 @@ func (s Set[T]) Size() int {
 	return len(s)
 }
 
-// String returns a string representation of the set
+// String returns a string representation of the set. Elements with commas, parentheses, quotes or spaces are quoted, so Parse can read it back
 func (s Set[T]) String() string {
 	var elements []string
 	for k := range s {
-		elements = append(elements, fmt.Sprintf("%v", k))
+		elements = append(elements, quoteElement(fmt.Sprintf("%v", k)))
 	}
 	// Sort for deterministic order
 	sort.Strings(elements)
 	return "(" + strings.Join(elements, ", ") + ")"
 }
 
-// Parse adds elements from input like "(a,b,c)" into the set.
+// Parse adds elements from input like "(a,b,c)" into the set. Quoted elements like "(a,\"b,c\")" are unquoted first.
 // A converter function is required to transform each token into T.
 func (s Set[T]) Parse(input string, convert func(string) (T, error)) error {
 	// remove surrounding parentheses
@@ -58,17 +58,20 @@ func (s Set[T]) Parse(input string, convert func(string) (T, error)) error {
 	if strings.HasPrefix(input, "(") && strings.HasSuffix(input, ")") {
 		input = input[1 : len(input)-1]
 	} else {
-		fmt.Printf("Don't have parantheses")
 		return fmt.Errorf("input string doesn't have surrounding parentheses")
 	}
 
 	// split and convert
-	for _, tok := range strings.Split(input, ",") {
-		trimmed := strings.TrimSpace(tok)
-		if trimmed == "" {
-			continue
+	tokens, err := splitElements(input)
+	if err != nil {
+		return err
+	}
+	for _, tok := range tokens {
+		unquoted, err := unquoteElement(tok)
+		if err != nil {
+			return err
 		}
-		val, err := convert(trimmed)
+		val, err := convert(unquoted)
 		if err != nil {
 			return err
 		}
@@ -95,3 +98,13 @@ func (s Set[T]) IsSubset(other Set[T]) bool {
 	}
 	return true
 }
+
+// sortedStrings returns the elements of a string set in sorted order, for deterministic iteration
+func sortedStrings(s Set[string]) []string {
+	elements := make([]string, 0, len(s))
+	for k := range s {
+		elements = append(elements, k)
+	}
+	sort.Strings(elements)
+	return elements
+}
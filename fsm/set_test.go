@@ -44,6 +44,13 @@ func TestParse(t *testing.T) {
 			expectedError:  nil,
 			convertFunc:    func(s string) (string, error) { return s, nil },
 		},
+		{
+			name:           "quoted elements test",
+			inputString:    `("(S0,S1)", "S 2",S3)`,
+			expectedOutput: NewSet("(S0,S1)", "S 2", "S3"),
+			expectedError:  nil,
+			convertFunc:    func(s string) (string, error) { return s, nil },
+		},
 		{
 			name:           "no paranthese test",
 			inputString:    "S0,S1,S2",
@@ -0,0 +1,271 @@
+package fsm
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SVGLayout selects how WriteSVG places the states
+type SVGLayout int
+
+const (
+	LayeredLayout  SVGLayout = iota // LayeredLayout puts states in columns by distance from q0, left to right
+	CircularLayout                  // CircularLayout puts states on a circle, in breadth-first order from q0 on the left
+)
+
+// SVGOptions configures WriteSVG
+type SVGOptions struct {
+	DiagramOptions           // DiagramOptions.Trace highlights a run, like in the other diagrams
+	Layout         SVGLayout // Layout selects how states are placed
+}
+
+const (
+	svgLayerSpacing = 150.0 // svgLayerSpacing is the distance between columns of the layered layout
+	svgRowSpacing   = 110.0 // svgRowSpacing is the distance between states of a column of the layered layout
+	svgMargin       = 80.0  // svgMargin leaves room around the states for self loops, labels and the start arrow
+	svgLoopHeight   = 60.0  // svgLoopHeight is how far the control points of a self loop are from its state
+)
+
+// svgPoint is a position in the SVG
+type svgPoint struct {
+	x, y float64
+}
+
+func (p svgPoint) add(q svgPoint) svgPoint     { return svgPoint{p.x + q.x, p.y + q.y} }
+func (p svgPoint) sub(q svgPoint) svgPoint     { return svgPoint{p.x - q.x, p.y - q.y} }
+func (p svgPoint) scale(k float64) svgPoint    { return svgPoint{p.x * k, p.y * k} }
+func (p svgPoint) length() float64             { return math.Hypot(p.x, p.y) }
+func (p svgPoint) unit() svgPoint              { return p.scale(1 / p.length()) }
+func (p svgPoint) normal() svgPoint            { return svgPoint{-p.y, p.x} }
+func (p svgPoint) distance(q svgPoint) float64 { return p.sub(q).length() }
+func (p svgPoint) String() string              { return fmt.Sprintf("%.1f,%.1f", p.x, p.y) }
+
+// towards returns the point at distance d from p in the direction of q
+func (p svgPoint) towards(q svgPoint, d float64) svgPoint {
+	return p.add(q.sub(p).unit().scale(d))
+}
+
+// polar returns the point at radius and angle from the origin
+func polar(radius, angle float64) svgPoint {
+	return svgPoint{radius * math.Cos(angle), radius * math.Sin(angle)}
+}
+
+// svgRadius returns the radius of the circle of a state, wide enough for its name
+func svgRadius(state string) float64 {
+	return math.Max(22, 4.5*float64(utf8.RuneCountInString(state))+10)
+}
+
+// breadthFirst returns the states in breadth-first order from q0, inputs in sorted order, and their distance from q0.
+// Unreachable states come last, sorted, one layer further than the furthest reachable state
+func (f *FiniteAutomaton) breadthFirst() ([]string, map[string]int) {
+	symbols := sortedStrings(f.Sigma)
+	order := []string{f.q0}
+	distance := map[string]int{f.q0: 0}
+	for i := 0; i < len(order); i++ {
+		for _, symbol := range symbols {
+			next := f.Delta[order[i]][symbol]
+			if _, seen := distance[next]; !seen {
+				distance[next] = distance[order[i]] + 1
+				order = append(order, next)
+			}
+		}
+	}
+	unreachable := distance[order[len(order)-1]] + 1
+	for _, state := range sortedStrings(f.Q) {
+		if _, seen := distance[state]; !seen {
+			distance[state] = unreachable
+			order = append(order, state)
+		}
+	}
+	return order, distance
+}
+
+// layeredPositions places states in columns by distance from q0, ordering every column by the mean row of the predecessors
+// in the previous column to limit crossings
+func (f *FiniteAutomaton) layeredPositions() map[string]svgPoint {
+	order, distance := f.breadthFirst()
+	var layers [][]string
+	for _, state := range order {
+		for len(layers) <= distance[state] {
+			layers = append(layers, nil)
+		}
+		layers[distance[state]] = append(layers[distance[state]], state)
+	}
+	row := make(map[string]int)
+	for i, layer := range layers {
+		barycenter := make(map[string]float64, len(layer))
+		for j, state := range layer {
+			sum, count := 0.0, 0
+			if i > 0 {
+				for _, from := range layers[i-1] {
+					for _, to := range f.Delta[from] {
+						if to == state {
+							sum += float64(row[from])
+							count++
+						}
+					}
+				}
+			}
+			barycenter[state] = float64(j)
+			if count > 0 {
+				barycenter[state] = sum / float64(count)
+			}
+		}
+		sort.SliceStable(layer, func(a, b int) bool { return barycenter[layer[a]] < barycenter[layer[b]] })
+		for j, state := range layer {
+			row[state] = j
+		}
+	}
+	positions := make(map[string]svgPoint, len(order))
+	for i, layer := range layers {
+		for j, state := range layer {
+			positions[state] = svgPoint{float64(i) * svgLayerSpacing, (float64(j) - float64(len(layer)-1)/2) * svgRowSpacing}
+		}
+	}
+	return positions
+}
+
+// circularPositions places states on a circle in breadth-first order, q0 on the left and going clockwise
+func (f *FiniteAutomaton) circularPositions() map[string]svgPoint {
+	order, _ := f.breadthFirst()
+	positions := make(map[string]svgPoint, len(order))
+	if len(order) == 1 {
+		positions[order[0]] = svgPoint{}
+		return positions
+	}
+	radius := math.Max(100, float64(len(order))*110/(2*math.Pi))
+	for i, state := range order {
+		positions[state] = polar(radius, math.Pi+2*math.Pi*float64(i)/float64(len(order)))
+	}
+	return positions
+}
+
+// segmentDistance returns the distance from p to the segment from a to b
+func segmentDistance(p, a, b svgPoint) float64 {
+	ab := b.sub(a)
+	t := ((p.x-a.x)*ab.x + (p.y-a.y)*ab.y) / (ab.x*ab.x + ab.y*ab.y)
+	t = math.Max(0, math.Min(1, t))
+	return p.distance(a.add(ab.scale(t)))
+}
+
+// svgEscape escapes text for SVG content and attributes
+var svgEscape = strings.NewReplacer(`&`, `&amp;`, `<`, `&lt;`, `>`, `&gt;`, `"`, `&quot;`)
+
+// WriteSVG renders the FA as a self-contained SVG image, without external tools like Graphviz. Final states have a double
+// circle, an arrow points into q0, self loops are curved above their state and parallel transitions share one labelled edge.
+// Edges are curved when they would overlap the reverse edge or cross another state. The output is deterministic
+func (f *FiniteAutomaton) WriteSVG(w io.Writer, options SVGOptions) error {
+	h, err := f.highlight(options.DiagramOptions)
+	if err != nil {
+		return err
+	}
+	var positions map[string]svgPoint
+	switch options.Layout {
+	case LayeredLayout:
+		positions = f.layeredPositions()
+	case CircularLayout:
+		positions = f.circularPositions()
+	default:
+		return fmt.Errorf("unknown SVG layout %d", options.Layout)
+	}
+	states := sortedStrings(f.Q)
+	radius := make(map[string]float64, len(states))
+	center := svgPoint{}
+	for _, state := range states {
+		radius[state] = svgRadius(state)
+		center = center.add(positions[state].scale(1 / float64(len(states))))
+	}
+
+	// bounding box, with room for loops, labels and the start arrow
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, state := range states {
+		p, r := positions[state], radius[state]+svgMargin
+		minX, minY = math.Min(minX, p.x-r), math.Min(minY, p.y-r)
+		maxX, maxY = math.Max(maxX, p.x+r), math.Max(maxY, p.y+r)
+	}
+
+	var b, labels strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="%.1f %.1f %.1f %.1f" font-family="sans-serif" font-size="14">`+"\n",
+		maxX-minX, maxY-minY, minX, minY, maxX-minX, maxY-minY)
+	b.WriteString("<defs>\n")
+	for _, marker := range [][2]string{{"arrow", "#333333"}, {"arrow-taken", "#e65100"}} {
+		fmt.Fprintf(&b, `<marker id="%s" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="7" markerHeight="7" orient="auto"><path d="M0,0 L10,5 L0,10 z" fill="%s"/></marker>`+"\n", marker[0], marker[1])
+	}
+	b.WriteString("</defs>\n")
+
+	// start arrow, coming from the left
+	q0 := positions[f.q0]
+	end := q0.sub(svgPoint{radius[f.q0], 0})
+	fmt.Fprintf(&b, `<path d="M%s L%s" fill="none" stroke="#333333" stroke-width="1.5" marker-end="url(#arrow)"/>`+"\n", end.sub(svgPoint{40, 0}), end)
+
+	// edges, with their labels kept aside to be drawn over the states
+	edges := f.diagramEdges()
+	exists := make(map[[2]string]bool, len(edges))
+	for _, e := range edges {
+		exists[[2]string{e.from, e.to}] = true
+	}
+	for _, e := range edges {
+		stroke, width, marker := "#333333", 1.5, "arrow"
+		if _, taken := h.steps[[2]string{e.from, e.to}]; taken {
+			stroke, width, marker = "#e65100", 3.0, "arrow-taken"
+		}
+		from, to := positions[e.from], positions[e.to]
+		var path string
+		var label svgPoint
+		if e.from == e.to {
+			// self loop away from the other states: above the state in the layered layout, outwards in the circular one
+			angle := -math.Pi / 2
+			if away := from.sub(center); options.Layout == CircularLayout && away.length() > 1 {
+				angle = math.Atan2(away.y, away.x)
+			}
+			r := radius[e.from]
+			start, finish := from.add(polar(r, angle-0.45)), from.add(polar(r, angle+0.45))
+			c1, c2 := from.add(polar(r+svgLoopHeight, angle-0.7)), from.add(polar(r+svgLoopHeight, angle+0.7))
+			path = fmt.Sprintf("M%s C%s %s %s", start, c1, c2, finish)
+			top := 0.25*r*math.Cos(0.45) + 0.75*(r+svgLoopHeight)*math.Cos(0.7) // furthest point of the loop from the state
+			label = from.add(polar(top+12, angle))
+		} else {
+			// curve the edge if the reverse edge exists or it would cross another state
+			bend := 0.0
+			if exists[[2]string{e.to, e.from}] {
+				bend = 25
+			}
+			for _, state := range states {
+				if state != e.from && state != e.to && segmentDistance(positions[state], from, to) < radius[state]+8 {
+					bend = math.Max(bend, 0.25*from.distance(to))
+				}
+			}
+			control := from.add(to).scale(0.5).add(to.sub(from).unit().normal().scale(bend))
+			start, finish := from.towards(control, radius[e.from]), to.towards(control, radius[e.to])
+			path = fmt.Sprintf("M%s Q%s %s", start, control, finish)
+			// middle of the curve, moved off the line on the outer side
+			middle := start.scale(0.25).add(control.scale(0.5)).add(finish.scale(0.25))
+			label = middle.add(to.sub(from).unit().normal().scale(12))
+		}
+		fmt.Fprintf(&b, `<path d="%s" fill="none" stroke="%s" stroke-width="%.1f" marker-end="url(#%s)"/>`+"\n", path, stroke, width, marker)
+		fmt.Fprintf(&labels, `<text x="%.1f" y="%.1f" text-anchor="middle" dominant-baseline="central" stroke="white" stroke-width="4" paint-order="stroke" fill="%s">%s</text>`+"\n",
+			label.x, label.y, stroke, svgEscape.Replace(e.label))
+	}
+
+	// states
+	for _, state := range states {
+		p, r := positions[state], radius[state]
+		fill := "white"
+		if h.visited.Contains(state) {
+			fill = "#ffd54f"
+		}
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s" stroke="#333333" stroke-width="1.5"/>`+"\n", p.x, p.y, r, fill)
+		if f.F.Contains(state) {
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="none" stroke="#333333" stroke-width="1.5"/>`+"\n", p.x, p.y, r-4)
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle" dominant-baseline="central">%s</text>`+"\n", p.x, p.y, svgEscape.Replace(state))
+	}
+	b.WriteString(labels.String())
+	b.WriteString("</svg>\n")
+	_, err = io.WriteString(w, b.String())
+	return err
+}
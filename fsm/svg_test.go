@@ -0,0 +1,173 @@
+package fsm
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+// svgElements parses svg, failing the test if it is not well formed XML, and counts its elements by name
+func svgElements(t *testing.T, svg string) map[string]int {
+	t.Helper()
+	counts := make(map[string]int)
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return counts
+		}
+		if err != nil {
+			t.Fatalf("WriteSVG() is not well formed XML: %v\n%s", err, svg)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			counts[start.Name.Local]++
+		}
+	}
+}
+
+func TestFiniteAutomaton_WriteSVG(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+	trace := &Trace{Steps: []TraceStep{{Position: 0, Symbol: "1", From: "S0", To: "S1"}}}
+
+	tests := []struct {
+		name        string
+		options     SVGOptions
+		wantCircles int
+		wantPaths   int
+		wantText    []string
+	}{
+		{
+			name:        "layered",
+			options:     SVGOptions{Layout: LayeredLayout},
+			wantCircles: 4, // 3 states, plus the inner circle of the final state
+			wantPaths:   9, // 6 edges, start arrow and 2 arrowheads
+			wantText:    []string{">S0</text>", ">S2</text>", ">0</text>", ">1</text>", `marker-end="url(#arrow)"`},
+		},
+		{
+			name:        "circular with trace",
+			options:     SVGOptions{DiagramOptions: DiagramOptions{Trace: trace}, Layout: CircularLayout},
+			wantCircles: 4,
+			wantPaths:   9,
+			wantText:    []string{`fill="#ffd54f"`, `marker-end="url(#arrow-taken)"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := threeModFA.WriteSVG(&b, tt.options); err != nil {
+				t.Fatalf("WriteSVG() error = %v", err)
+			}
+			counts := svgElements(t, b.String())
+			if counts["circle"] != tt.wantCircles || counts["path"] != tt.wantPaths {
+				t.Errorf("WriteSVG() has %d circles and %d paths, want %d and %d", counts["circle"], counts["path"], tt.wantCircles, tt.wantPaths)
+			}
+			for _, want := range tt.wantText {
+				if !strings.Contains(b.String(), want) {
+					t.Errorf("WriteSVG() doesn't contain %s\n%s", want, b.String())
+				}
+			}
+			var again strings.Builder
+			_ = threeModFA.WriteSVG(&again, tt.options)
+			if again.String() != b.String() {
+				t.Errorf("WriteSVG() is not deterministic")
+			}
+		})
+	}
+
+	var b strings.Builder
+	if err := threeModFA.WriteSVG(&b, SVGOptions{Layout: SVGLayout(7)}); err == nil {
+		t.Errorf("WriteSVG() expected error on unknown layout")
+	}
+}
+
+func TestFiniteAutomaton_WriteSVGEscaping(t *testing.T) {
+
+	fa, _ := NewFiniteAutomaton(
+		NewSet("a<b", `"q"&`),
+		NewSet("<", "&"), "a<b", NewSet(`"q"&`),
+		map[string]map[string]string{
+			"a<b":  {"<": `"q"&`, "&": "a<b"},
+			`"q"&`: {"<": `"q"&`, "&": "a<b"},
+		})
+	var b strings.Builder
+	if err := fa.WriteSVG(&b, SVGOptions{}); err != nil {
+		t.Fatalf("WriteSVG() error = %v", err)
+	}
+	svgElements(t, b.String())
+	if !strings.Contains(b.String(), ">a&lt;b</text>") {
+		t.Errorf("WriteSVG() should escape state names\n%s", b.String())
+	}
+}
+
+func TestFiniteAutomaton_layeredPositions(t *testing.T) {
+
+	// S0 -> S1 -> S2 in a line, S3 unreachable
+	lineFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2", "S3"),
+		NewSet("a"), "S0", NewSet("S2"),
+		map[string]map[string]string{
+			"S0": {"a": "S1"},
+			"S1": {"a": "S2"},
+			"S2": {"a": "S2"},
+			"S3": {"a": "S0"},
+		})
+	positions := lineFA.layeredPositions()
+	want := map[string]svgPoint{"S0": {0, 0}, "S1": {svgLayerSpacing, 0}, "S2": {2 * svgLayerSpacing, 0}, "S3": {3 * svgLayerSpacing, 0}}
+	for state, p := range want {
+		if positions[state] != p {
+			t.Errorf("layeredPositions()[%s] = %v, want %v", state, positions[state], p)
+		}
+	}
+}
+
+func TestFiniteAutomaton_circularPositions(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+	positions := threeModFA.circularPositions()
+	if p := positions["S0"]; p.x >= 0 || math.Abs(p.y) > 1e-9 {
+		t.Errorf("circularPositions() puts q0 at %v, want it on the left", p)
+	}
+	radius := positions["S0"].length()
+	for state, p := range positions {
+		if math.Abs(p.length()-radius) > 1e-9 {
+			t.Errorf("circularPositions()[%s] = %v is not on the circle of radius %v", state, p, radius)
+		}
+	}
+}
+
+func TestSegmentDistance(t *testing.T) {
+
+	tests := []struct {
+		name string
+		p    svgPoint
+		want float64
+	}{
+		{name: "above the middle", p: svgPoint{5, 3}, want: 3},
+		{name: "past the end", p: svgPoint{13, 4}, want: 5},
+		{name: "on the segment", p: svgPoint{2, 0}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := segmentDistance(tt.p, svgPoint{0, 0}, svgPoint{10, 0}); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("segmentDistance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package fsm
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// StateChange is the notification sent to subscribers of a SyncFiniteStateMachine after every transition
+type StateChange struct {
+	Symbol string // Symbol is the input rune that caused the transition
+	From   string // From is the state before the transition
+	To     string // To is the state after the transition
+}
+
+// SyncFiniteStateMachine is a thread-safe wrapper around a FiniteStateMachine, so one machine can be driven from several goroutines.
+//
+//	Transitions are serialized with a mutex, CurrentState is an atomic read that never blocks on a running transition.
+//	Hooks, Tracer and OutputConverter of the wrapped FSM should be set up before wrapping it and not touched directly afterwards.
+//
+//	Hooks and OutputConverter run while the lock is held. They may call CurrentState, but calling ProcessInputRune,
+//	GetFSMOutput, ProcessReader, Output or Subscribe of the same machine from them deadlocks
+type SyncFiniteStateMachine struct {
+	mu          sync.Mutex
+	fsm         *FiniteStateMachine
+	state       atomic.Pointer[string]
+	subscribers map[int]chan StateChange
+	nextID      int
+}
+
+// NewSyncFiniteStateMachine wraps f in a SyncFiniteStateMachine. f must not be used directly afterwards
+func NewSyncFiniteStateMachine(f *FiniteStateMachine) *SyncFiniteStateMachine {
+	s := &SyncFiniteStateMachine{fsm: f, subscribers: make(map[int]chan StateChange)}
+	state := f.currentState
+	s.state.Store(&state)
+	return s
+}
+
+// NewSyncFiniteStateMachine returns a new thread-safe FiniteStateMachine with initialized state
+func (f *FiniteAutomaton) NewSyncFiniteStateMachine() *SyncFiniteStateMachine {
+	return NewSyncFiniteStateMachine(f.NewFiniteStateMachine())
+}
+
+// String returns a string representing the SyncFiniteStateMachine as a string
+func (s *SyncFiniteStateMachine) String() string {
+	return fmt.Sprintf("SyncFSM: \n\tstate=%s\n\tFA=%s\n", s.CurrentState(), s.fsm.FA.String())
+}
+
+// CurrentState returns the state the machine is currently in. It is safe to call concurrently with transitions
+func (s *SyncFiniteStateMachine) CurrentState() string {
+	return *s.state.Load()
+}
+
+// Subscribe returns a channel receiving a StateChange after every transition, and a func to cancel the subscription.
+//
+//	Notifications are sent without blocking: if the channel buffer is full the notification is dropped for that subscriber.
+//	The channel is closed when the subscription is cancelled
+func (s *SyncFiniteStateMachine) Subscribe(buffer int) (<-chan StateChange, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan StateChange, buffer)
+	id := s.nextID
+	s.nextID++
+	s.subscribers[id] = ch
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.subscribers, id)
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+// ProcessInputRune processes a single input rune under the machine's lock. See FiniteStateMachine.ProcessInputRune
+func (s *SyncFiniteStateMachine) ProcessInputRune(inputRune string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.processInputRune(inputRune)
+}
+
+// GetFSMOutput processes the whole input under the machine's lock, so no other goroutine can interleave transitions.
+//
+//	See FiniteStateMachine.GetFSMOutput
+func (s *SyncFiniteStateMachine) GetFSMOutput(input string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range input {
+		if err := s.processInputRune(string(r)); err != nil {
+			return 0, err
+		}
+	}
+	return s.fsm.output()
+}
+
+// processInputRune processes the rune, publishes the new state and notifies subscribers. Caller must hold s.mu
+func (s *SyncFiniteStateMachine) processInputRune(inputRune string) error {
+	from := s.fsm.currentState
+	if err := s.fsm.ProcessInputRune(inputRune); err != nil {
+		return err
+	}
+	to := s.fsm.currentState
+	s.state.Store(&to)
+
+	change := StateChange{Symbol: inputRune, From: from, To: to}
+	for _, ch := range s.subscribers {
+		select {
+		case ch <- change:
+		default: // subscriber is not keeping up, drop the notification
+		}
+	}
+	return nil
+}
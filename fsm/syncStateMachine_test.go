@@ -0,0 +1,134 @@
+package fsm
+
+import (
+	"sync"
+	"testing"
+)
+
+// run these with "go test -race" (make test-race) to catch unsynchronized access
+
+func TestSyncFiniteStateMachine_ConcurrentTransitions(t *testing.T) {
+
+	// counts "1"s modulo 4, so the final state only depends on how many runes were processed, not their interleaving
+	countFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2", "S3"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2", "S3"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S1", "1": "S2"},
+			"S2": {"0": "S2", "1": "S3"},
+			"S3": {"0": "S3", "1": "S0"},
+		})
+
+	machine := countFA.NewSyncFiniteStateMachine()
+	changes, cancel := machine.Subscribe(1000)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 10; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if err := machine.ProcessInputRune("1"); err != nil {
+					t.Errorf("SyncFiniteStateMachine.ProcessInputRune() error = %v", err)
+				}
+				_ = machine.CurrentState() // concurrent atomic read
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 500 "1"s, 500 % 4 == 0
+	if got := machine.CurrentState(); got != "S0" {
+		t.Errorf("SyncFiniteStateMachine.CurrentState() = %v, want S0", got)
+	}
+
+	cancel()
+	count := 0
+	last := "S0"
+	for change := range changes {
+		if change.From != last {
+			t.Errorf("StateChange %v doesn't follow previous state %v", change, last)
+		}
+		last = change.To
+		count++
+	}
+	if count != 500 {
+		t.Errorf("received %d state changes, want 500", count)
+	}
+}
+
+func TestFiniteAutomaton_SharedAcrossMachines(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+
+	var wg sync.WaitGroup
+	for g := 0; g < 20; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			machine := threeModFA.NewFiniteStateMachine() // one machine per goroutine, one shared FA
+			got, err := machine.GetFSMOutput("1101")
+			if err != nil || got != 1 {
+				t.Errorf("FiniteStateMachine.GetFSMOutput() = %v, %v, want 1", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSyncFiniteStateMachine_GetFSMOutput(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+
+	machine := threeModFA.NewSyncFiniteStateMachine()
+	got, err := machine.GetFSMOutput("1101")
+	if err != nil || got != 1 {
+		t.Errorf("SyncFiniteStateMachine.GetFSMOutput() = %v, %v, want 1", got, err)
+	}
+	if _, err := machine.GetFSMOutput("2"); err == nil {
+		t.Errorf("SyncFiniteStateMachine.GetFSMOutput() expected error on bad input")
+	}
+}
+
+func TestSyncFiniteStateMachine_HookReadsCurrentState(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+
+	// CurrentState doesn't take the lock, so a hook can read it while its transition runs
+	fsm := threeModFA.NewFiniteStateMachine()
+	var machine *SyncFiniteStateMachine
+	var seen []string
+	fsm.OnEnterAny(func(symbol string, from string, to string) error {
+		seen = append(seen, machine.CurrentState())
+		return nil
+	})
+	machine = NewSyncFiniteStateMachine(fsm)
+	if _, err := machine.GetFSMOutput("11"); err != nil {
+		t.Fatalf("SyncFiniteStateMachine.GetFSMOutput() error = %v", err)
+	}
+	if len(seen) != 2 || seen[0] != "S0" || seen[1] != "S1" {
+		t.Errorf("CurrentState() seen from hooks = %v, want [S0 S1]", seen)
+	}
+}
@@ -0,0 +1,188 @@
+package fsm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The text format is what FiniteAutomaton.String prints, so textbook definitions can be pasted straight into tests:
+//
+//	FA:
+//		Q=(S0, S1, S2)
+//		Σ=(0, 1)
+//		q0=S0
+//		F=(S0, S1, S2)
+//		δ=((S0, 0, S0), (S0, 1, S1), (S1, 0, S2), (S1, 1, S0), (S2, 0, S1), (S2, 1, S2))
+//
+// δ is the set of (state, input, next state) triples. Elements that are empty or contain commas, parentheses, quotes or
+// spaces are written as Go quoted strings, like "(S0,S1)" or " ".
+
+// quoteElement returns s as written in the text format, quoted if it can't be written as is
+func quoteElement(s string) string {
+	if s == "" || strings.ContainsAny(s, `,()"`) || strings.IndexFunc(s, unicode.IsSpace) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// unquoteElement reverses quoteElement on a trimmed element
+func unquoteElement(element string) (string, error) {
+	if !strings.HasPrefix(element, `"`) {
+		return element, nil
+	}
+	s, err := strconv.Unquote(element)
+	if err != nil {
+		return "", fmt.Errorf("bad quoted element %s", element)
+	}
+	return s, nil
+}
+
+// splitElements splits the content of a "(a,b,c)" list on its top level commas, leaving quoted elements and nested lists
+// whole. Elements are trimmed and still quoted. Empty elements are dropped
+func splitElements(input string) ([]string, error) {
+	var elements []string
+	depth, quoted, escaped, start := 0, false, false, 0
+	for i, r := range input {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			if depth--; depth < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses in %s", input)
+			}
+		case r == ',' && depth == 0:
+			if element := strings.TrimSpace(input[start:i]); element != "" {
+				elements = append(elements, element)
+			}
+			start = i + 1
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in %s", input)
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses in %s", input)
+	}
+	if element := strings.TrimSpace(input[start:]); element != "" {
+		elements = append(elements, element)
+	}
+	return elements, nil
+}
+
+// parseTuple parses "(a,b,c)" into its unquoted elements
+func parseTuple(input string) ([]string, error) {
+	input = strings.TrimSpace(input)
+	if !strings.HasPrefix(input, "(") || !strings.HasSuffix(input, ")") {
+		return nil, fmt.Errorf("%s doesn't have surrounding parentheses", input)
+	}
+	elements, err := splitElements(input[1 : len(input)-1])
+	if err != nil {
+		return nil, err
+	}
+	for i, element := range elements {
+		if elements[i], err = unquoteElement(element); err != nil {
+			return nil, err
+		}
+	}
+	return elements, nil
+}
+
+// deltaString returns Delta as the sorted set of (state, input, next state) triples
+func (f *FiniteAutomaton) deltaString() string {
+	var triples []string
+	for _, from := range sortedStrings(f.Q) {
+		for _, input := range sortedStrings(f.Sigma) {
+			if to, exists := f.Delta[from][input]; exists {
+				triples = append(triples, "("+quoteElement(from)+", "+quoteElement(input)+", "+quoteElement(to)+")")
+			}
+		}
+	}
+	return "(" + strings.Join(triples, ", ") + ")"
+}
+
+// ParseFiniteAutomaton parses an FA in the text format printed by FiniteAutomaton.String.
+//
+//	The "FA:" header is optional and the lines can come in any order. Sigma and delta can be spelled out instead of Σ and δ.
+//	The result goes through the same validation as NewFiniteAutomaton
+func ParseFiniteAutomaton(input string) (*FiniteAutomaton, error) {
+	keys := map[string]string{"Q": "Q", "Σ": "Σ", "Sigma": "Σ", "q0": "q0", "F": "F", "δ": "δ", "delta": "δ"}
+	values := make(map[string]string)
+	for n, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "FA:" && len(values) == 0 {
+			continue
+		}
+		name, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected name=value, got %s", n+1, line)
+		}
+		key, known := keys[strings.TrimSpace(name)]
+		if !known {
+			return nil, fmt.Errorf("line %d: unknown name %s, expected one of Q, Σ, q0, F, δ", n+1, strings.TrimSpace(name))
+		}
+		if _, exists := values[key]; exists {
+			return nil, fmt.Errorf("line %d: %s is defined twice", n+1, key)
+		}
+		values[key] = strings.TrimSpace(value)
+	}
+	for _, key := range []string{"Q", "Σ", "q0", "F", "δ"} {
+		if _, exists := values[key]; !exists {
+			return nil, fmt.Errorf("%s is missing", key)
+		}
+	}
+
+	identity := func(s string) (string, error) { return s, nil }
+	Q, Sigma, F := NewSet[string](), NewSet[string](), NewSet[string]()
+	if err := Q.Parse(values["Q"], identity); err != nil {
+		return nil, fmt.Errorf("Q: %w", err)
+	}
+	if err := Sigma.Parse(values["Σ"], identity); err != nil {
+		return nil, fmt.Errorf("Σ: %w", err)
+	}
+	if err := F.Parse(values["F"], identity); err != nil {
+		return nil, fmt.Errorf("F: %w", err)
+	}
+	q0, err := unquoteElement(values["q0"])
+	if err != nil {
+		return nil, fmt.Errorf("q0: %w", err)
+	}
+
+	delta := make(map[string]map[string]string)
+	deltaValue := values["δ"]
+	if !strings.HasPrefix(deltaValue, "(") || !strings.HasSuffix(deltaValue, ")") {
+		return nil, fmt.Errorf("δ: %s doesn't have surrounding parentheses", deltaValue)
+	}
+	triples, err := splitElements(deltaValue[1 : len(deltaValue)-1])
+	if err != nil {
+		return nil, fmt.Errorf("δ: %w", err)
+	}
+	for _, triple := range triples {
+		t, err := parseTuple(triple)
+		if err != nil {
+			return nil, fmt.Errorf("δ: %w", err)
+		}
+		if len(t) != 3 {
+			return nil, fmt.Errorf("δ: %s should be (state, input, next state)", triple)
+		}
+		if !Q.Contains(t[2]) {
+			return nil, fmt.Errorf("δ: %s goes to %s, which is not one of the states in Q", triple, t[2])
+		}
+		if delta[t[0]] == nil {
+			delta[t[0]] = make(map[string]string)
+		}
+		if previous, exists := delta[t[0]][t[1]]; exists {
+			return nil, fmt.Errorf("δ: transition from %s on input %s is defined twice (to %s and %s)", t[0], t[1], previous, t[2])
+		}
+		delta[t[0]][t[1]] = t[2]
+	}
+	return NewFiniteAutomaton(Q, Sigma, q0, F, delta)
+}
@@ -0,0 +1,88 @@
+package fsm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFiniteAutomaton(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+	// states and inputs that need quoting
+	quotedFA, _ := NewFiniteAutomaton(
+		NewSet("(S0,S1)", "S 1"),
+		NewSet(",", "(", " ", `"`), "(S0,S1)", NewSet("S 1"),
+		map[string]map[string]string{
+			"(S0,S1)": {",": "S 1", "(": "(S0,S1)", " ": "(S0,S1)", `"`: "S 1"},
+			"S 1":     {",": "S 1", "(": "(S0,S1)", " ": "S 1", `"`: "(S0,S1)"},
+		})
+
+	tests := []struct {
+		name string
+		fa   *FiniteAutomaton
+	}{
+		{name: "threeModFA round trip", fa: threeModFA},
+		{name: "quoted elements round trip", fa: quotedFA},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFiniteAutomaton(tt.fa.String())
+			if err != nil {
+				t.Fatalf("ParseFiniteAutomaton() error = %v, input \n%s", err, tt.fa)
+			}
+			if !got.Equals(tt.fa) || got.String() != tt.fa.String() {
+				t.Errorf("ParseFiniteAutomaton() = \n%v\n want \n%v", got, tt.fa)
+			}
+		})
+	}
+
+	// textbook style, lines in any order without the header
+	textbook := `
+		q0 = S0
+		Sigma = (0,1)
+		Q = (S0,S1,S2)
+		F = (S0,S1,S2)
+		delta = ((S0,0,S0),(S0,1,S1),(S1,0,S2),(S1,1,S0),(S2,0,S1),(S2,1,S2))
+	`
+	got, err := ParseFiniteAutomaton(textbook)
+	if err != nil || !got.Equals(threeModFA) {
+		t.Errorf("ParseFiniteAutomaton() = %v, %v, want %v", got, err, threeModFA)
+	}
+}
+
+func TestParseFiniteAutomatonErrors(t *testing.T) {
+
+	valid := "Q=(S0)\nΣ=(a)\nq0=S0\nF=(S0)\nδ=((S0,a,S0))"
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "not name=value", input: "FA:\nQ(S0)", wantErr: "line 2: expected name=value"},
+		{name: "unknown name", input: "Z=(S0)", wantErr: "unknown name Z"},
+		{name: "defined twice", input: valid + "\nQ=(S0)", wantErr: "line 6: Q is defined twice"},
+		{name: "missing", input: "Q=(S0)", wantErr: "Σ is missing"},
+		{name: "set without parentheses", input: strings.Replace(valid, "F=(S0)", "F=S0", 1), wantErr: "F:"},
+		{name: "unterminated quote", input: strings.Replace(valid, "Q=(S0)", `Q=("S0)`, 1), wantErr: "unterminated quote"},
+		{name: "bad triple", input: strings.Replace(valid, "(S0,a,S0)", "(S0,a)", 1), wantErr: "should be (state, input, next state)"},
+		{name: "unknown target", input: strings.Replace(valid, "(S0,a,S0)", "(S0,a,bogus)", 1), wantErr: "δ: (S0,a,bogus) goes to bogus, which is not one of the states in Q"},
+		{name: "duplicate transition", input: strings.Replace(valid, "((S0,a,S0))", "((S0,a,S0),(S0,a,S0))", 1), wantErr: "defined twice"},
+		{name: "unbalanced delta", input: strings.Replace(valid, "((S0,a,S0))", "((S0,a,S0)))", 1), wantErr: "unbalanced parentheses"},
+		{name: "invalid FA", input: strings.Replace(valid, "q0=S0", "q0=S1", 1), wantErr: "q0(initial state)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFiniteAutomaton(tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFiniteAutomaton() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
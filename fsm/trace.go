@@ -0,0 +1,112 @@
+package fsm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// TraceStep records a single transition taken by a FiniteStateMachine
+type TraceStep struct {
+	Position int    `json:"position"` // Position is the index of the input rune that triggered this step, counted from the start of the machine
+	Symbol   string `json:"symbol"`   // Symbol is the input rune that was processed
+	From     string `json:"from"`     // From is the state before processing Symbol
+	To       string `json:"to"`       // To is the state after processing Symbol
+}
+
+// Trace is the ordered list of steps a FiniteStateMachine went through. Attach one to FiniteStateMachine.Tracer to record a run
+type Trace struct {
+	Steps []TraceStep `json:"steps"`
+}
+
+// NewTrace creates a new empty Trace
+func NewTrace() *Trace {
+	return &Trace{Steps: []TraceStep{}}
+}
+
+// Record appends a step to the trace
+func (t *Trace) Record(step TraceStep) {
+	t.Steps = append(t.Steps, step)
+}
+
+// Reset clears all recorded steps, so the trace can be reused for another run
+func (t *Trace) Reset() {
+	t.Steps = t.Steps[:0]
+}
+
+// Len returns the number of recorded steps
+func (t *Trace) Len() int {
+	return len(t.Steps)
+}
+
+// Path returns the trace as a compact path like "S0 -1-> S1 -0-> S2". Returns an empty string if nothing was recorded
+func (t *Trace) Path() string {
+	if len(t.Steps) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(t.Steps[0].From)
+	for _, step := range t.Steps {
+		fmt.Fprintf(&sb, " -%s-> %s", step.Symbol, step.To)
+	}
+	return sb.String()
+}
+
+// String returns the trace as a table with one row per step
+func (t *Trace) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-8s %-8s %-8s %-8s\n", "POS", "SYMBOL", "FROM", "TO")
+	for _, step := range t.Steps {
+		fmt.Fprintf(&sb, "%-8d %-8s %-8s %-8s\n", step.Position, step.Symbol, step.From, step.To)
+	}
+	return sb.String()
+}
+
+// JSON exports the trace as indented JSON
+func (t *Trace) JSON() ([]byte, error) {
+	return json.MarshalIndent(t, "", "  ")
+}
+
+// ParseTrace reads a trace previously exported with Trace.JSON
+func ParseTrace(data []byte) (*Trace, error) {
+	t := NewTrace()
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("could not parse trace. Error: %v", err)
+	}
+	return t, nil
+}
+
+// TraceDivergence describes the first step where a replayed automaton disagrees with a recorded trace
+type TraceDivergence struct {
+	Step     TraceStep // Step is the recorded step at which the divergence happened
+	Expected string    // Expected is the state the recorded trace went to (or started from, for a divergence in initial state)
+	Got      string    // Got is the state the replayed automaton went to
+}
+
+// String returns a human readable description of the divergence
+func (d *TraceDivergence) String() string {
+	return fmt.Sprintf("divergence at position %d on symbol %s from state %s: expected %s, got %s", d.Step.Position, d.Step.Symbol, d.Step.From, d.Expected, d.Got)
+}
+
+// Replay runs the recorded symbols through fa, starting from its initial state, and returns the first divergence from the recorded states.
+//
+//	Returns nil if fa follows the trace exactly. Returns an error if a recorded symbol is not in fa's Sigma
+func (t *Trace) Replay(fa *FiniteAutomaton) (*TraceDivergence, error) {
+	if len(t.Steps) == 0 {
+		return nil, nil
+	}
+	state := fa.q0
+	if state != t.Steps[0].From { // the two runs don't even start from the same place
+		return &TraceDivergence{Step: t.Steps[0], Expected: t.Steps[0].From, Got: state}, nil
+	}
+	for _, step := range t.Steps {
+		if !fa.Sigma.Contains(step.Symbol) {
+			return nil, fmt.Errorf("rune %v at position %d is not an acceptable input", step.Symbol, step.Position)
+		}
+		state = fa.Delta[state][step.Symbol]
+		if state != step.To {
+			return &TraceDivergence{Step: step, Expected: step.To, Got: state}, nil
+		}
+	}
+	return nil, nil
+}
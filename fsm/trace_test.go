@@ -0,0 +1,152 @@
+package fsm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFiniteStateMachine_Tracer(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+
+	fsm := threeModFA.NewFiniteStateMachine()
+	fsm.Tracer = NewTrace()
+	if _, err := fsm.GetFSMOutput("110"); err != nil {
+		t.Fatalf("FiniteStateMachine.GetFSMOutput() error = %v", err)
+	}
+
+	want := []TraceStep{
+		{Position: 0, Symbol: "1", From: "S0", To: "S1"},
+		{Position: 1, Symbol: "1", From: "S1", To: "S0"},
+		{Position: 2, Symbol: "0", From: "S0", To: "S0"},
+	}
+	if len(fsm.Tracer.Steps) != len(want) {
+		t.Fatalf("Trace.Steps = %v, want %v", fsm.Tracer.Steps, want)
+	}
+	for i := range want {
+		if fsm.Tracer.Steps[i] != want[i] {
+			t.Errorf("Trace.Steps[%d] = %v, want %v", i, fsm.Tracer.Steps[i], want[i])
+		}
+	}
+	if got := fsm.Tracer.Path(); got != "S0 -1-> S1 -1-> S0 -0-> S0" {
+		t.Errorf("Trace.Path() = %v", got)
+	}
+	if got := fsm.Tracer.String(); !strings.Contains(got, "POS") || strings.Count(got, "\n") != 4 {
+		t.Errorf("Trace.String() = \n%v", got)
+	}
+}
+
+func TestFiniteStateMachine_TracerRejectedPath(t *testing.T) {
+
+	threeModFA_MissingFinalState, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+
+	fsm := threeModFA_MissingFinalState.NewFiniteStateMachine()
+	fsm.Tracer = NewTrace()
+	_, err := fsm.GetFSMOutput("1101")
+	if err == nil {
+		t.Fatalf("FiniteStateMachine.GetFSMOutput() expected error")
+	}
+	if !strings.Contains(err.Error(), "path=S0 -1-> S1 -1-> S0 -0-> S0 -1-> S1") {
+		t.Errorf("FiniteStateMachine.GetFSMOutput() error = %v, expected it to contain the path", err)
+	}
+
+	// a rune outside Sigma reports where it was rejected
+	fsm = threeModFA_MissingFinalState.NewFiniteStateMachine()
+	fsm.Tracer = NewTrace()
+	_, err = fsm.GetFSMOutput("10x")
+	if err == nil || !strings.Contains(err.Error(), "rune x at position 2 is not an acceptable input. path=S0 -1-> S1 -0-> S2") {
+		t.Errorf("FiniteStateMachine.GetFSMOutput() error = %v, expected it to contain the position and path", err)
+	}
+	fsm = threeModFA_MissingFinalState.NewFiniteStateMachine()
+	fsm.Tracer = NewTrace()
+	if _, err = fsm.GetFSMOutput("x"); err == nil || !strings.Contains(err.Error(), "position 0 is not an acceptable input. path=S0") {
+		t.Errorf("FiniteStateMachine.GetFSMOutput() error = %v, expected it to contain the position and path", err)
+	}
+}
+
+func TestTrace_JSONAndReplay(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+	// same as threeModFA except S2 on "1"
+	brokenFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S0"},
+		})
+	otherSigmaFA, _ := NewFiniteAutomaton(
+		NewSet("S0"), NewSet("a"), "S0", NewSet("S0"),
+		map[string]map[string]string{"S0": {"a": "S0"}})
+
+	fsm := threeModFA.NewFiniteStateMachine()
+	fsm.Tracer = NewTrace()
+	if _, err := fsm.GetFSMOutput("10111"); err != nil {
+		t.Fatalf("FiniteStateMachine.GetFSMOutput() error = %v", err)
+	}
+
+	data, err := fsm.Tracer.JSON()
+	if err != nil {
+		t.Fatalf("Trace.JSON() error = %v", err)
+	}
+	trace, err := ParseTrace(data)
+	if err != nil {
+		t.Fatalf("ParseTrace() error = %v", err)
+	}
+	if trace.Path() != fsm.Tracer.Path() {
+		t.Errorf("ParseTrace() path = %v, want %v", trace.Path(), fsm.Tracer.Path())
+	}
+
+	tests := []struct {
+		name    string
+		fa      *FiniteAutomaton
+		wantPos int // -1 means no divergence
+		wantErr bool
+	}{
+		{name: "same automaton", fa: threeModFA, wantPos: -1},
+		{name: "diverging automaton", fa: brokenFA, wantPos: 2},
+		{name: "different alphabet", fa: otherSigmaFA, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := trace.Replay(tt.fa)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Trace.Replay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.wantPos == -1 {
+				if got != nil {
+					t.Errorf("Trace.Replay() = %v, want no divergence", got)
+				}
+				return
+			}
+			if got == nil || got.Step.Position != tt.wantPos {
+				t.Errorf("Trace.Replay() = %v, want divergence at %d", got, tt.wantPos)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package fsm
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// LeftEndMarker and RightEndMarker delimit the input on the tape of a TwoWayAutomaton
+const (
+	LeftEndMarker  = "⊢"
+	RightEndMarker = "⊣"
+)
+
+// Direction is the move of the head of a TwoWayAutomaton
+type Direction int
+
+const (
+	Left  Direction = -1 // Left moves the head one cell to the left
+	Right Direction = 1  // Right moves the head one cell to the right
+)
+
+// TwoWayMove is the result of a TwoWayAutomaton transition: the next state and where the head goes
+type TwoWayMove struct {
+	To   string
+	Move Direction
+}
+
+// TwoWayOutcome is how a run of a TwoWayAutomaton ends
+type TwoWayOutcome int
+
+const (
+	TwoWayAccepted TwoWayOutcome = iota // TwoWayAccepted means the head moved right off RightEndMarker in a state of F
+	TwoWayRejected                      // TwoWayRejected means the head moved right off RightEndMarker in a state not in F, or there was no transition
+	TwoWayLooped                        // TwoWayLooped means the run went back to a state and position it had already been in, so it never halts
+)
+
+func (o TwoWayOutcome) String() string {
+	switch o {
+	case TwoWayAccepted:
+		return "accepted"
+	case TwoWayRejected:
+		return "rejected"
+	case TwoWayLooped:
+		return "looped"
+	}
+	return "unknown"
+}
+
+// TwoWayAutomaton represents a two-way deterministic finite automaton (2DFA), whose head can move left or right over the input.
+//
+//	The input is read between LeftEndMarker and RightEndMarker. The run ends when the head moves right off RightEndMarker,
+//	accepting if it does so in a state of F. A missing transition rejects
+type TwoWayAutomaton struct {
+	Q     Set[string]                      // Q is the set of states
+	Sigma Set[string]                      // Sigma is the set of inputs, without the end markers
+	q0    string                           // q0 is the initial state, with the head on LeftEndMarker
+	F     Set[string]                      // F is the set of final states
+	Delta map[string]map[string]TwoWayMove // Delta is a map between state, input (or end marker) and the move. Can be partial
+}
+
+// NewTwoWayAutomaton creates a new 2DFA with the tuple (Q,Σ,q0,F,δ). Does initial error checking as well.
+func NewTwoWayAutomaton(Q Set[string], Sigma Set[string], q0 string, F Set[string], Delta map[string]map[string]TwoWayMove) (*TwoWayAutomaton, error) {
+	if len(Q) == 0 {
+		return nil, fmt.Errorf("Q(list of acceptable states) is empty")
+	}
+	if len(Sigma) == 0 {
+		return nil, fmt.Errorf("Σ(Sigma)=(list of acceptable input) is empty")
+	}
+	if Sigma.Contains(LeftEndMarker) || Sigma.Contains(RightEndMarker) {
+		return nil, fmt.Errorf("Σ(Sigma) can't contain the end markers %s and %s", LeftEndMarker, RightEndMarker)
+	}
+	if !Q.Contains(q0) {
+		return nil, fmt.Errorf("q0(initial state) is not one of the acceptable states")
+	}
+	if len(F) == 0 {
+		return nil, fmt.Errorf("F(list of acceptable final states) is empty")
+	}
+	if !F.IsSubset(Q) {
+		return nil, fmt.Errorf("F(list of acceptable final states) is not a subset of Q")
+	}
+	tw := TwoWayAutomaton{Q: Q.DeepCopy(), Sigma: Sigma.DeepCopy(), q0: q0, F: F.DeepCopy(), Delta: make(map[string]map[string]TwoWayMove, len(Delta))}
+
+	// deep copy Delta and error check in the process
+	for state, bySymbol := range Delta {
+		if !tw.Q.Contains(state) {
+			return nil, fmt.Errorf("delta state %s is not one of the acceptable states", state)
+		}
+		tw.Delta[state] = make(map[string]TwoWayMove, len(bySymbol))
+		for symbol, move := range bySymbol {
+			if !tw.Sigma.Contains(symbol) && symbol != LeftEndMarker && symbol != RightEndMarker {
+				return nil, fmt.Errorf("delta input %s of state %s is not an acceptable input", symbol, state)
+			}
+			if !tw.Q.Contains(move.To) {
+				return nil, fmt.Errorf("delta of state %s on input %s goes to %s, which is not one of the acceptable states", state, symbol, move.To)
+			}
+			if move.Move != Left && move.Move != Right {
+				return nil, fmt.Errorf("delta of state %s on input %s has an invalid move %d", state, symbol, move.Move)
+			}
+			if symbol == LeftEndMarker && move.Move == Left {
+				return nil, fmt.Errorf("delta of state %s moves left off the left end marker", state)
+			}
+			tw.Delta[state][symbol] = move
+		}
+	}
+	return &tw, nil
+}
+
+// String returns a string representing the TwoWayAutomaton as a string
+func (tw *TwoWayAutomaton) String() string {
+	return fmt.Sprintf("2DFA:\n\tQ=%s\n\tΣ=%s\n\tq0=%v\n\tF=%s\n\tδ=%v\n", tw.Q.String(), tw.Sigma.String(), tw.q0, tw.F.String(), tw.Delta)
+}
+
+// Run simulates the automaton on input and returns how the run ends. Returns an error if input has a rune outside of Sigma
+func (tw *TwoWayAutomaton) Run(input string) (TwoWayOutcome, error) {
+	tape := []string{LeftEndMarker}
+	for _, r := range input {
+		if !tw.Sigma.Contains(string(r)) {
+			return TwoWayRejected, fmt.Errorf("rune %v is not an acceptable input", string(r))
+		}
+		tape = append(tape, string(r))
+	}
+	tape = append(tape, RightEndMarker)
+
+	type configuration struct {
+		state    string
+		position int
+	}
+	seen := make(map[configuration]bool)
+	current := configuration{tw.q0, 0}
+	for {
+		if seen[current] { // deterministic, so coming back to a configuration means looping forever
+			return TwoWayLooped, nil
+		}
+		seen[current] = true
+		move, exists := tw.Delta[current.state][tape[current.position]]
+		if !exists {
+			return TwoWayRejected, nil
+		}
+		next := configuration{move.To, current.position + int(move.Move)}
+		if next.position == len(tape) { // moved right off the right end marker
+			if tw.F.Contains(next.state) {
+				return TwoWayAccepted, nil
+			}
+			return TwoWayRejected, nil
+		}
+		current = next
+	}
+}
+
+// Accepts reports whether input is accepted. A looping run is not accepted
+func (tw *TwoWayAutomaton) Accepts(input string) (bool, error) {
+	outcome, err := tw.Run(input)
+	return outcome == TwoWayAccepted, err
+}
+
+// crossingTable summarizes how the automaton crosses the boundary right of a prefix of the tape, which determines every
+// crossing sequence at that boundary. first is the state in which the run from q0 first crosses it to the right.
+// exit[p] is the state in which the head crosses it to the right again after coming back into the prefix in state p.
+// noCrossing marks runs that halt or loop inside the prefix
+type crossingTable struct {
+	first string
+	exit  []string // exit is indexed like states
+}
+
+// noCrossing marks a run that never crosses back to the right
+const noCrossing = "\x00"
+
+// key returns a string identifying the table
+func (t crossingTable) key() string {
+	return tupleState(append([]string{t.first}, t.exit...)...)
+}
+
+// exitRight returns the state in which the head, entering the cell holding symbol in state s, crosses to the right of that
+// cell, using table to go through the prefix on its left. Returns noCrossing if it halts or loops
+func (tw *TwoWayAutomaton) exitRight(table crossingTable, index map[string]int, symbol string, s string) string {
+	seen := NewSet[string]()
+	for !seen.Contains(s) {
+		seen.Add(s)
+		move, exists := tw.Delta[s][symbol]
+		if !exists {
+			return noCrossing
+		}
+		if move.Move == Right {
+			return move.To
+		}
+		s = table.exit[index[move.To]] // back into the prefix, and out again
+		if s == noCrossing {
+			return noCrossing
+		}
+	}
+	return noCrossing // came back to the same state on this cell: looping
+}
+
+// extend returns the table of the prefix followed by symbol
+func (tw *TwoWayAutomaton) extend(table crossingTable, states []string, index map[string]int, symbol string) crossingTable {
+	next := crossingTable{first: noCrossing, exit: make([]string, len(states))}
+	if table.first != noCrossing {
+		next.first = tw.exitRight(table, index, symbol, table.first)
+	}
+	for i, p := range states {
+		next.exit[i] = tw.exitRight(table, index, symbol, p)
+	}
+	return next
+}
+
+// ToFiniteAutomaton converts the 2DFA into an equivalent one-way FiniteAutomaton, with Shepherdson's construction: the state
+// after a prefix is its crossingTable, which captures every crossing sequence the 2DFA can produce at the prefix's right boundary.
+//
+//	States of the result are named "T0", "T1", ... in discovery order. Returns an error if the 2DFA accepts no input at all
+func (tw *TwoWayAutomaton) ToFiniteAutomaton() (*FiniteAutomaton, error) {
+	states := sortedStrings(tw.Q)
+	index := make(map[string]int, len(states))
+	for i, state := range states {
+		index[state] = i
+	}
+	symbols := sortedStrings(tw.Sigma)
+
+	// table of the prefix made of the left end marker only: it can only be left by moving right
+	start := crossingTable{first: noCrossing, exit: make([]string, len(states))}
+	if move, exists := tw.Delta[tw.q0][LeftEndMarker]; exists {
+		start.first = move.To
+	}
+	for i, p := range states {
+		start.exit[i] = noCrossing
+		if move, exists := tw.Delta[p][LeftEndMarker]; exists {
+			start.exit[i] = move.To
+		}
+	}
+
+	// accepts reports whether the run, once the table's prefix is the whole input, accepts on the right end marker
+	accepts := func(table crossingTable) bool {
+		if table.first == noCrossing {
+			return false
+		}
+		final := tw.exitRight(table, index, RightEndMarker, table.first)
+		return final != noCrossing && tw.F.Contains(final)
+	}
+
+	names := map[string]string{start.key(): "T0"}
+	Q := NewSet("T0")
+	F := NewSet[string]()
+	Delta := make(map[string]map[string]string)
+	queue := []crossingTable{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		name := names[current.key()]
+		if accepts(current) {
+			F.Add(name)
+		}
+		Delta[name] = make(map[string]string, len(symbols))
+		for _, symbol := range symbols {
+			next := tw.extend(current, states, index, symbol)
+			nextName, exists := names[next.key()]
+			if !exists {
+				nextName = "T" + strconv.Itoa(len(names))
+				names[next.key()] = nextName
+				Q.Add(nextName)
+				queue = append(queue, next)
+			}
+			Delta[name][symbol] = nextName
+		}
+	}
+	if F.Size() == 0 {
+		return nil, fmt.Errorf("2DFA accepts no input, it has no equivalent FiniteAutomaton with a non empty F")
+	}
+	return NewFiniteAutomaton(Q, tw.Sigma, "T0", F, Delta)
+}
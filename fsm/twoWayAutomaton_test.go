@@ -0,0 +1,101 @@
+package fsm
+
+import (
+	"testing"
+)
+
+// newEndsWithA returns a 2DFA that scans to the right end marker, steps back once and checks that the last input is "a".
+// On "b" it rescans from the left end marker when loop is true, which makes it loop forever
+func newEndsWithA(t *testing.T, loop bool) *TwoWayAutomaton {
+	delta := map[string]map[string]TwoWayMove{
+		"scan":   {LeftEndMarker: {"scan", Right}, "a": {"scan", Right}, "b": {"scan", Right}, RightEndMarker: {"check", Left}},
+		"check":  {"a": {"accept", Right}},
+		"accept": {RightEndMarker: {"accept", Right}},
+		"rewind": {"a": {"rewind", Left}, "b": {"rewind", Left}, LeftEndMarker: {"scan", Right}},
+	}
+	if loop {
+		delta["check"]["b"] = TwoWayMove{"rewind", Left}
+	}
+	tw, err := NewTwoWayAutomaton(NewSet("scan", "check", "accept", "rewind"), NewSet("a", "b"), "scan", NewSet("accept"), delta)
+	if err != nil {
+		t.Fatalf("NewTwoWayAutomaton() error = %v", err)
+	}
+	return tw
+}
+
+func TestTwoWayAutomaton_Run(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		loop  bool
+		input string
+		want  TwoWayOutcome
+	}{
+		{name: "ends with a", input: "bba", want: TwoWayAccepted},
+		{name: "ends with b", input: "aab", want: TwoWayRejected},
+		{name: "empty", input: "", want: TwoWayRejected},
+		{name: "ends with a, looping machine", loop: true, input: "ba", want: TwoWayAccepted},
+		{name: "ends with b, looping machine", loop: true, input: "ab", want: TwoWayLooped},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newEndsWithA(t, tt.loop).Run(tt.input)
+			if err != nil || got != tt.want {
+				t.Errorf("TwoWayAutomaton.Run(%s) = %v, %v, want %v", tt.input, got, err, tt.want)
+			}
+		})
+	}
+	if _, err := newEndsWithA(t, false).Run("abc"); err == nil {
+		t.Errorf("TwoWayAutomaton.Run() expected error for a bad input")
+	}
+}
+
+func TestTwoWayAutomaton_ToFiniteAutomaton(t *testing.T) {
+
+	for _, loop := range []bool{false, true} {
+		tw := newEndsWithA(t, loop)
+		fa, err := tw.ToFiniteAutomaton()
+		if err != nil {
+			t.Fatalf("ToFiniteAutomaton() error = %v", err)
+		}
+
+		// compare on every input up to length 6
+		inputs := []string{""}
+		for length := 0; length <= 6; length++ {
+			var longer []string
+			for _, input := range inputs {
+				want, _ := tw.Accepts(input)
+				fsm := fa.NewFiniteStateMachine()
+				fsm.OutputConverter = func(string) (int, error) { return 0, nil }
+				_, err := fsm.GetFSMOutput(input)
+				if got := err == nil; got != want {
+					t.Errorf("loop %v input %q: FA accepts = %v, 2DFA accepts = %v", loop, input, got, want)
+				}
+				longer = append(longer, input+"a", input+"b")
+			}
+			inputs = longer
+		}
+	}
+}
+
+func TestNewTwoWayAutomaton(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		Sigma Set[string]
+		Delta map[string]map[string]TwoWayMove
+	}{
+		{name: "end marker in Sigma", Sigma: NewSet("a", LeftEndMarker), Delta: nil},
+		{name: "left off the tape", Sigma: NewSet("a"), Delta: map[string]map[string]TwoWayMove{"S0": {LeftEndMarker: {"S0", Left}}}},
+		{name: "bad move", Sigma: NewSet("a"), Delta: map[string]map[string]TwoWayMove{"S0": {"a": {"S0", 0}}}},
+		{name: "bad target", Sigma: NewSet("a"), Delta: map[string]map[string]TwoWayMove{"S0": {"a": {"S9", Right}}}},
+		{name: "bad input", Sigma: NewSet("a"), Delta: map[string]map[string]TwoWayMove{"S0": {"b": {"S0", Right}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTwoWayAutomaton(NewSet("S0"), tt.Sigma, "S0", NewSet("S0"), tt.Delta); err == nil {
+				t.Errorf("NewTwoWayAutomaton() expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package fsm
+
+import (
+	"fmt"
+	"math"
+)
+
+// Semiring is the algebra of weights of a WeightedAutomaton.
+//
+//	Plus combines the weights of alternative paths, Times the weights along a path. Zero is the identity of Plus (no path),
+//	One the identity of Times (empty path). Better reports whether a is preferred over b when picking a best path
+type Semiring[W comparable] interface {
+	Zero() W
+	One() W
+	Plus(a W, b W) W
+	Times(a W, b W) W
+	Better(a W, b W) bool
+}
+
+// TropicalSemiring is (min, +) over float64: the weight of an input is the cost of its cheapest path
+type TropicalSemiring struct{}
+
+func (TropicalSemiring) Zero() float64                      { return math.Inf(1) }
+func (TropicalSemiring) One() float64                       { return 0 }
+func (TropicalSemiring) Plus(a float64, b float64) float64  { return math.Min(a, b) }
+func (TropicalSemiring) Times(a float64, b float64) float64 { return a + b }
+func (TropicalSemiring) Better(a float64, b float64) bool   { return a < b }
+
+// ProbabilitySemiring is (+, ×) over float64: the weight of an input is the total probability of its paths
+type ProbabilitySemiring struct{}
+
+func (ProbabilitySemiring) Zero() float64                      { return 0 }
+func (ProbabilitySemiring) One() float64                       { return 1 }
+func (ProbabilitySemiring) Plus(a float64, b float64) float64  { return a + b }
+func (ProbabilitySemiring) Times(a float64, b float64) float64 { return a * b }
+func (ProbabilitySemiring) Better(a float64, b float64) bool   { return a > b }
+
+// BooleanSemiring is (∨, ∧) over bool: the weight of an input is whether it is accepted, like FiniteAutomaton
+type BooleanSemiring struct{}
+
+func (BooleanSemiring) Zero() bool                 { return false }
+func (BooleanSemiring) One() bool                  { return true }
+func (BooleanSemiring) Plus(a bool, b bool) bool   { return a || b }
+func (BooleanSemiring) Times(a bool, b bool) bool  { return a && b }
+func (BooleanSemiring) Better(a bool, b bool) bool { return a && !b }
+
+// CountingSemiring is (+, ×) over int: with all weights One, the weight of an input is its number of accepting paths
+type CountingSemiring struct{}
+
+func (CountingSemiring) Zero() int                { return 0 }
+func (CountingSemiring) One() int                 { return 1 }
+func (CountingSemiring) Plus(a int, b int) int    { return a + b }
+func (CountingSemiring) Times(a int, b int) int   { return a * b }
+func (CountingSemiring) Better(a int, b int) bool { return a > b }
+
+// WeightedTransition is a transition of a WeightedAutomaton carrying a weight
+type WeightedTransition[W comparable] struct {
+	From   string
+	Symbol string
+	To     string
+	Weight W
+}
+
+// WeightedAutomaton represents a weighted automaton over a Semiring. It extends the Delta of FiniteAutomaton to scored, possibly
+// nondeterministic, transitions.
+//
+//	Initial and Final give the initial and final weight of states. States missing from them have weight Zero
+type WeightedAutomaton[W comparable] struct {
+	Q           Set[string]             // Q is the set of states
+	Sigma       Set[string]             // Sigma is the set of inputs
+	Initial     map[string]W            // Initial is the initial weight of states
+	Final       map[string]W            // Final is the final weight of states
+	Transitions []WeightedTransition[W] // Transitions is the list of weighted transitions
+	Semiring    Semiring[W]             // Semiring is the algebra used to combine weights
+
+	outgoing map[string]map[string][]WeightedTransition[W] // outgoing indexes Transitions by state and input
+}
+
+// NewWeightedAutomaton creates a new WeightedAutomaton. Does initial error checking as well.
+func NewWeightedAutomaton[W comparable](Q Set[string], Sigma Set[string], initial map[string]W, final map[string]W, transitions []WeightedTransition[W], semiring Semiring[W]) (*WeightedAutomaton[W], error) {
+	if len(Q) == 0 {
+		return nil, fmt.Errorf("Q(list of acceptable states) is empty")
+	}
+	if len(Sigma) == 0 {
+		return nil, fmt.Errorf("Σ(Sigma)=(list of acceptable input) is empty")
+	}
+	if semiring == nil {
+		return nil, fmt.Errorf("semiring is nil")
+	}
+	wa := WeightedAutomaton[W]{
+		Q:        Q.DeepCopy(),
+		Sigma:    Sigma.DeepCopy(),
+		Initial:  make(map[string]W, len(initial)),
+		Final:    make(map[string]W, len(final)),
+		Semiring: semiring,
+		outgoing: make(map[string]map[string][]WeightedTransition[W]),
+	}
+	for state, w := range initial {
+		if !wa.Q.Contains(state) {
+			return nil, fmt.Errorf("initial state %s is not one of the acceptable states", state)
+		}
+		wa.Initial[state] = w
+	}
+	if len(wa.Initial) == 0 {
+		return nil, fmt.Errorf("no initial state")
+	}
+	for state, w := range final {
+		if !wa.Q.Contains(state) {
+			return nil, fmt.Errorf("final state %s is not one of the acceptable states", state)
+		}
+		wa.Final[state] = w
+	}
+	if len(wa.Final) == 0 {
+		return nil, fmt.Errorf("F(list of acceptable final states) is empty")
+	}
+	for _, t := range transitions {
+		if !wa.Q.Contains(t.From) || !wa.Q.Contains(t.To) {
+			return nil, fmt.Errorf("transition %s -%s-> %s uses a state that is not one of the acceptable states", t.From, t.Symbol, t.To)
+		}
+		if !wa.Sigma.Contains(t.Symbol) {
+			return nil, fmt.Errorf("transition %s -%s-> %s uses an input that is not an acceptable input", t.From, t.Symbol, t.To)
+		}
+		wa.Transitions = append(wa.Transitions, t)
+		if wa.outgoing[t.From] == nil {
+			wa.outgoing[t.From] = make(map[string][]WeightedTransition[W])
+		}
+		wa.outgoing[t.From][t.Symbol] = append(wa.outgoing[t.From][t.Symbol], t)
+	}
+	return &wa, nil
+}
+
+// NewWeightedFromFiniteAutomaton lifts fa into a WeightedAutomaton where every transition of Delta gets weight(from, symbol, to).
+//
+//	q0 and the states of F get weight One. With BooleanSemiring and a weight func returning true, ShortestDistance is fa's acceptance
+func NewWeightedFromFiniteAutomaton[W comparable](fa *FiniteAutomaton, semiring Semiring[W], weight func(from string, symbol string, to string) W) (*WeightedAutomaton[W], error) {
+	var transitions []WeightedTransition[W]
+	for _, from := range sortedStrings(fa.Q) {
+		for _, symbol := range sortedStrings(fa.Sigma) {
+			to := fa.Delta[from][symbol]
+			transitions = append(transitions, WeightedTransition[W]{From: from, Symbol: symbol, To: to, Weight: weight(from, symbol, to)})
+		}
+	}
+	final := make(map[string]W, len(fa.F))
+	for state := range fa.F {
+		final[state] = semiring.One()
+	}
+	return NewWeightedAutomaton(fa.Q, fa.Sigma, map[string]W{fa.q0: semiring.One()}, final, transitions, semiring)
+}
+
+// String returns a string representing the WeightedAutomaton as a string
+func (w *WeightedAutomaton[W]) String() string {
+	return fmt.Sprintf("WFA:\n\tQ=%s\n\tΣ=%s\n\tI=%v\n\tF=%v\n\tδ=%v\n", w.Q.String(), w.Sigma.String(), w.Initial, w.Final, w.Transitions)
+}
+
+// ShortestDistance returns the ⊕-sum over all accepting paths labeled by input of the ⊗-product of their weights,
+// including initial and final weights. Returns Zero if there is no accepting path
+func (w *WeightedAutomaton[W]) ShortestDistance(input string) (W, error) {
+	sr := w.Semiring
+	states := sortedStrings(w.Q)
+
+	distance := make(map[string]W, len(w.Initial))
+	for state, weight := range w.Initial {
+		distance[state] = weight
+	}
+	for _, r := range input {
+		symbol := string(r)
+		if !w.Sigma.Contains(symbol) {
+			return sr.Zero(), fmt.Errorf("rune %v is not an acceptable input", symbol)
+		}
+		next := make(map[string]W)
+		for _, state := range states {
+			d, reached := distance[state]
+			if !reached || d == sr.Zero() {
+				continue
+			}
+			for _, t := range w.outgoing[state][symbol] {
+				old, exists := next[t.To]
+				if !exists {
+					old = sr.Zero()
+				}
+				next[t.To] = sr.Plus(old, sr.Times(d, t.Weight))
+			}
+		}
+		distance = next
+	}
+
+	total := sr.Zero()
+	for _, state := range states {
+		d, reached := distance[state]
+		final, isFinal := w.Final[state]
+		if reached && isFinal {
+			total = sr.Plus(total, sr.Times(d, final))
+		}
+	}
+	return total, nil
+}
+
+// BestPath returns the accepting path labeled by input with the best weight according to Semiring.Better, as the list of
+// states visited (len(input runes)+1 long), with its weight. Ties are broken by state name. Returns an error if there is no accepting path
+func (w *WeightedAutomaton[W]) BestPath(input string) ([]string, W, error) {
+	sr := w.Semiring
+	states := sortedStrings(w.Q)
+
+	type cell struct {
+		weight W
+		prev   string
+	}
+	var columns []map[string]cell // columns[i][state] is the best way to reach state after i runes
+
+	first := make(map[string]cell, len(w.Initial))
+	for _, state := range states {
+		if weight, exists := w.Initial[state]; exists && weight != sr.Zero() {
+			first[state] = cell{weight: weight}
+		}
+	}
+	columns = append(columns, first)
+
+	for _, r := range input {
+		symbol := string(r)
+		if !w.Sigma.Contains(symbol) {
+			return nil, sr.Zero(), fmt.Errorf("rune %v is not an acceptable input", symbol)
+		}
+		current := columns[len(columns)-1]
+		next := make(map[string]cell)
+		for _, state := range states {
+			c, reached := current[state]
+			if !reached {
+				continue
+			}
+			for _, t := range w.outgoing[state][symbol] {
+				weight := sr.Times(c.weight, t.Weight)
+				if weight == sr.Zero() {
+					continue
+				}
+				old, exists := next[t.To]
+				if !exists || sr.Better(weight, old.weight) {
+					next[t.To] = cell{weight: weight, prev: state}
+				}
+			}
+		}
+		columns = append(columns, next)
+	}
+
+	last := columns[len(columns)-1]
+	found := false
+	bestState := ""
+	best := sr.Zero()
+	for _, state := range states {
+		c, reached := last[state]
+		final, isFinal := w.Final[state]
+		if !reached || !isFinal {
+			continue
+		}
+		weight := sr.Times(c.weight, final)
+		if weight == sr.Zero() {
+			continue
+		}
+		if !found || sr.Better(weight, best) {
+			found, bestState, best = true, state, weight
+		}
+	}
+	if !found {
+		return nil, sr.Zero(), fmt.Errorf("input %q has no accepting path", input)
+	}
+
+	// follow back pointers from the best final state
+	path := make([]string, len(columns))
+	state := bestState
+	for i := len(columns) - 1; i >= 0; i-- {
+		path[i] = state
+		state = columns[i][state].prev
+	}
+	return path, best, nil
+}
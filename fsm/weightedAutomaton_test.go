@@ -0,0 +1,113 @@
+package fsm
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestWeightedAutomaton_BooleanMatchesFiniteAutomaton(t *testing.T) {
+
+	threeModFA_MissingFinalState, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+	wa, err := NewWeightedFromFiniteAutomaton[bool](threeModFA_MissingFinalState, BooleanSemiring{}, func(string, string, string) bool { return true })
+	if err != nil {
+		t.Fatalf("NewWeightedFromFiniteAutomaton() error = %v", err)
+	}
+
+	for i := 0; i <= 64; i++ {
+		input := strconv.FormatInt(int64(i), 2)
+		_, fsmErr := threeModFA_MissingFinalState.NewFiniteStateMachine().GetFSMOutput(input)
+		got, err := wa.ShortestDistance(input)
+		if err != nil {
+			t.Fatalf("WeightedAutomaton.ShortestDistance() error = %v", err)
+		}
+		if got != (fsmErr == nil) {
+			t.Errorf("WeightedAutomaton.ShortestDistance(%s) = %v, FSM error = %v", input, got, fsmErr)
+		}
+	}
+}
+
+// newAmbiguousTransitions returns transitions over (a) where S0 can reach S2 on "aa" through S1 (cost 1+1) or S3 (cost 0.5+3)
+func newAmbiguousTransitions[W comparable](w1 W, w2 W, w3 W, w4 W) []WeightedTransition[W] {
+	return []WeightedTransition[W]{
+		{From: "S0", Symbol: "a", To: "S1", Weight: w1},
+		{From: "S1", Symbol: "a", To: "S2", Weight: w2},
+		{From: "S0", Symbol: "a", To: "S3", Weight: w3},
+		{From: "S3", Symbol: "a", To: "S2", Weight: w4},
+	}
+}
+
+func TestWeightedAutomaton_Semirings(t *testing.T) {
+
+	Q := NewSet("S0", "S1", "S2", "S3")
+	Sigma := NewSet("a")
+
+	tropical, _ := NewWeightedAutomaton(Q, Sigma, map[string]float64{"S0": 0}, map[string]float64{"S2": 0},
+		newAmbiguousTransitions(1.0, 1.0, 0.5, 3.0), TropicalSemiring{})
+	if got, _ := tropical.ShortestDistance("aa"); got != 2 {
+		t.Errorf("tropical ShortestDistance() = %v, want 2", got)
+	}
+	path, weight, err := tropical.BestPath("aa")
+	if err != nil || weight != 2 || !reflect.DeepEqual(path, []string{"S0", "S1", "S2"}) {
+		t.Errorf("tropical BestPath() = %v, %v, %v, want [S0 S1 S2], 2", path, weight, err)
+	}
+
+	probability, _ := NewWeightedAutomaton(Q, Sigma, map[string]float64{"S0": 1}, map[string]float64{"S2": 1},
+		newAmbiguousTransitions(0.25, 1.0, 0.75, 0.5), ProbabilitySemiring{})
+	if got, _ := probability.ShortestDistance("aa"); got != 0.625 {
+		t.Errorf("probability ShortestDistance() = %v, want 0.625", got)
+	}
+	path, weight, _ = probability.BestPath("aa")
+	if weight != 0.375 || !reflect.DeepEqual(path, []string{"S0", "S3", "S2"}) {
+		t.Errorf("probability BestPath() = %v, %v, want [S0 S3 S2], 0.375", path, weight)
+	}
+
+	counting, _ := NewWeightedAutomaton(Q, Sigma, map[string]int{"S0": 1}, map[string]int{"S2": 1},
+		newAmbiguousTransitions(1, 1, 1, 1), CountingSemiring{})
+	if got, _ := counting.ShortestDistance("aa"); got != 2 {
+		t.Errorf("counting ShortestDistance() = %v, want 2", got)
+	}
+	if got, _ := counting.ShortestDistance("a"); got != 0 {
+		t.Errorf("counting ShortestDistance() = %v, want 0", got)
+	}
+	if _, _, err := counting.BestPath("a"); err == nil {
+		t.Errorf("counting BestPath() expected error for input with no accepting path")
+	}
+	if _, err := counting.ShortestDistance("b"); err == nil {
+		t.Errorf("counting ShortestDistance() expected error for bad input")
+	}
+}
+
+func TestNewWeightedAutomaton(t *testing.T) {
+
+	Q := NewSet("S0", "S1")
+	Sigma := NewSet("a")
+	tests := []struct {
+		name        string
+		initial     map[string]int
+		final       map[string]int
+		transitions []WeightedTransition[int]
+		wantErr     bool
+	}{
+		{name: "valid", initial: map[string]int{"S0": 1}, final: map[string]int{"S1": 1}, transitions: []WeightedTransition[int]{{From: "S0", Symbol: "a", To: "S1", Weight: 1}}},
+		{name: "no initial", initial: map[string]int{}, final: map[string]int{"S1": 1}, wantErr: true},
+		{name: "no final", initial: map[string]int{"S0": 1}, final: map[string]int{}, wantErr: true},
+		{name: "unknown state", initial: map[string]int{"S0": 1}, final: map[string]int{"S1": 1}, transitions: []WeightedTransition[int]{{From: "S0", Symbol: "a", To: "S9", Weight: 1}}, wantErr: true},
+		{name: "unknown input", initial: map[string]int{"S0": 1}, final: map[string]int{"S1": 1}, transitions: []WeightedTransition[int]{{From: "S0", Symbol: "b", To: "S1", Weight: 1}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWeightedAutomaton(Q, Sigma, tt.initial, tt.final, tt.transitions, CountingSemiring{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewWeightedAutomaton() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
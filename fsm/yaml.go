@@ -0,0 +1,240 @@
+package fsm
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAML definition files describe an FA for people who don't write Go. Transitions are a table of state → input → target,
+// and outputs optionally give the OutputConverter value of states:
+//
+//	states: [S0, S1, S2]
+//	alphabet: ["0", "1"]
+//	initial: S0
+//	accepting: [S0, S1, S2]
+//	transitions:
+//	  S0: {"0": S0, "1": S1}  # comments are fine anywhere
+//	  S1: {"0": S2, "1": S0}
+//	  S2: {"0": S1, "1": S2}
+//	outputs:
+//	  S0: 0
+//	  S1: 1
+//	  S2: 2
+
+// yamlKeys are the top level keys of a YAML definition
+var yamlKeys = NewSet("states", "alphabet", "initial", "accepting", "transitions", "outputs")
+
+// yamlError returns an error pointing at the line of node
+func yamlError(node *yaml.Node, format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", node.Line, fmt.Sprintf(format, args...))
+}
+
+// yamlScalars reads a sequence of scalars, rejecting duplicates
+func yamlScalars(node *yaml.Node, what string) (Set[string], error) {
+	if node.Kind != yaml.SequenceNode {
+		return nil, yamlError(node, "%s should be a list", what)
+	}
+	s := NewSet[string]()
+	for _, item := range node.Content {
+		if item.Kind != yaml.ScalarNode {
+			return nil, yamlError(item, "%s should only contain plain values", what)
+		}
+		if s.Contains(item.Value) {
+			return nil, yamlError(item, "%s %s is listed twice", what, item.Value)
+		}
+		s.Add(item.Value)
+	}
+	return s, nil
+}
+
+// LoadYAML reads an FA, and its outputs if the definition has any, from a YAML definition.
+//
+//	Errors on invalid states or inputs report the line they are on. outputs is nil if the definition has no outputs
+func LoadYAML(data []byte) (*FiniteAutomaton, OutputMap[int], error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("line 1: definition should be a mapping of states, alphabet, initial, accepting and transitions")
+	}
+	root := doc.Content[0]
+	nodes := make(map[string]*yaml.Node)
+	for i := 0; i < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if !yamlKeys.Contains(key.Value) {
+			return nil, nil, yamlError(key, "unknown key %s, expected one of %s", key.Value, yamlKeys)
+		}
+		if _, exists := nodes[key.Value]; exists {
+			return nil, nil, yamlError(key, "key %s is defined twice", key.Value)
+		}
+		nodes[key.Value] = value
+	}
+	for _, required := range []string{"states", "alphabet", "initial", "accepting", "transitions"} {
+		if _, exists := nodes[required]; !exists {
+			return nil, nil, yamlError(root, "missing key %s", required)
+		}
+	}
+
+	Q, err := yamlScalars(nodes["states"], "state")
+	if err != nil {
+		return nil, nil, err
+	}
+	Sigma, err := yamlScalars(nodes["alphabet"], "input")
+	if err != nil {
+		return nil, nil, err
+	}
+	initial := nodes["initial"]
+	if initial.Kind != yaml.ScalarNode || !Q.Contains(initial.Value) {
+		return nil, nil, yamlError(initial, "initial state %s is not one of the states", initial.Value)
+	}
+	F, err := yamlScalars(nodes["accepting"], "accepting state")
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, item := range nodes["accepting"].Content {
+		if !Q.Contains(item.Value) {
+			return nil, nil, yamlError(item, "accepting state %s is not one of the states", item.Value)
+		}
+	}
+
+	// transitions table, state → input → target
+	table := nodes["transitions"]
+	if table.Kind != yaml.MappingNode {
+		return nil, nil, yamlError(table, "transitions should be a mapping of state to a mapping of input to state")
+	}
+	delta := make(map[string]map[string]string)
+	for i := 0; i < len(table.Content); i += 2 {
+		from, row := table.Content[i], table.Content[i+1]
+		if !Q.Contains(from.Value) {
+			return nil, nil, yamlError(from, "state %s is not one of the states", from.Value)
+		}
+		if _, exists := delta[from.Value]; exists {
+			return nil, nil, yamlError(from, "transitions of state %s are defined twice", from.Value)
+		}
+		if row.Kind != yaml.MappingNode {
+			return nil, nil, yamlError(row, "transitions of state %s should be a mapping of input to state", from.Value)
+		}
+		delta[from.Value] = make(map[string]string)
+		for j := 0; j < len(row.Content); j += 2 {
+			input, to := row.Content[j], row.Content[j+1]
+			if !Sigma.Contains(input.Value) {
+				return nil, nil, yamlError(input, "input %s of state %s is not in the alphabet", input.Value, from.Value)
+			}
+			if _, exists := delta[from.Value][input.Value]; exists {
+				return nil, nil, yamlError(input, "transition of state %s on input %s is defined twice", from.Value, input.Value)
+			}
+			if to.Kind != yaml.ScalarNode || !Q.Contains(to.Value) {
+				return nil, nil, yamlError(to, "target %s of state %s on input %s is not one of the states", to.Value, from.Value, input.Value)
+			}
+			delta[from.Value][input.Value] = to.Value
+		}
+		for _, input := range sortedStrings(Sigma) {
+			if _, exists := delta[from.Value][input]; !exists {
+				return nil, nil, yamlError(row, "state %s has no transition on input %s", from.Value, input)
+			}
+		}
+	}
+	for _, state := range sortedStrings(Q) {
+		if _, exists := delta[state]; !exists {
+			return nil, nil, yamlError(table, "state %s has no transitions", state)
+		}
+	}
+
+	fa, err := NewFiniteAutomaton(Q, Sigma, initial.Value, F, delta)
+	if err != nil {
+		return nil, nil, yamlError(root, "%v", err)
+	}
+
+	outputsNode, hasOutputs := nodes["outputs"]
+	if !hasOutputs {
+		return fa, nil, nil
+	}
+	if outputsNode.Kind != yaml.MappingNode {
+		return nil, nil, yamlError(outputsNode, "outputs should be a mapping of state to int")
+	}
+	outputs := make(map[string]int)
+	for i := 0; i < len(outputsNode.Content); i += 2 {
+		state, value := outputsNode.Content[i], outputsNode.Content[i+1]
+		if !Q.Contains(state.Value) {
+			return nil, nil, yamlError(state, "output state %s is not one of the states", state.Value)
+		}
+		var output int
+		if err := value.Decode(&output); err != nil {
+			return nil, nil, yamlError(value, "output %s of state %s is not an int", value.Value, state.Value)
+		}
+		outputs[state.Value] = output
+	}
+	m, err := NewOutputMap(fa, outputs)
+	if err != nil {
+		return nil, nil, yamlError(outputsNode, "%v", err)
+	}
+	return fa, m, nil
+}
+
+// LoadYAMLFile reads an FA definition from a YAML file. See LoadYAML. Errors are prefixed with the file name
+func LoadYAMLFile(path string) (*FiniteAutomaton, OutputMap[int], error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	fa, outputs, err := LoadYAML(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s:%w", path, err)
+	}
+	return fa, outputs, nil
+}
+
+// yamlString returns a scalar node for s, quoted when YAML would read it as something else than a string
+func yamlString(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
+
+// yamlList returns a one line sequence node of the sorted elements of s
+func yamlList(s Set[string]) *yaml.Node {
+	list := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+	for _, e := range sortedStrings(s) {
+		list.Content = append(list.Content, yamlString(e))
+	}
+	return list
+}
+
+// ToYAML writes the FA, and outputs if not nil, as a YAML definition that LoadYAML reads back. Transitions of a state go on one line
+func (f *FiniteAutomaton) ToYAML(outputs OutputMap[int]) ([]byte, error) {
+	table := &yaml.Node{Kind: yaml.MappingNode}
+	for _, from := range sortedStrings(f.Q) {
+		row := &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}
+		for _, input := range sortedStrings(f.Sigma) {
+			row.Content = append(row.Content, yamlString(input), yamlString(f.Delta[from][input]))
+		}
+		table.Content = append(table.Content, yamlString(from), row)
+	}
+	root := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		yamlString("states"), yamlList(f.Q),
+		yamlString("alphabet"), yamlList(f.Sigma),
+		yamlString("initial"), yamlString(f.q0),
+		yamlString("accepting"), yamlList(f.F),
+		yamlString("transitions"), table,
+	}}
+	if outputs != nil {
+		outputsNode := &yaml.Node{Kind: yaml.MappingNode}
+		for _, state := range sortedStrings(f.Q) {
+			if output, exists := outputs[state]; exists {
+				outputsNode.Content = append(outputsNode.Content, yamlString(state), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprint(output)})
+			}
+		}
+		root.Content = append(root.Content, yamlString("outputs"), outputsNode)
+	}
+	return yaml.Marshal(root)
+}
+
+// SaveYAMLFile writes the FA, and outputs if not nil, to a YAML file. See ToYAML
+func (f *FiniteAutomaton) SaveYAMLFile(path string, outputs OutputMap[int]) error {
+	data, err := f.ToYAML(outputs)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,134 @@
+package fsm
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const threeModYAML = `# remainder of a binary number divided by 3
+states: [S0, S1, S2]
+alphabet: ["0", "1"]
+initial: S0
+accepting: [S0, S1, S2]
+transitions:
+  S0: {"0": S0, "1": S1} # remainder 0
+  S1: {"0": S2, "1": S0}
+  S2:
+    "0": S1
+    "1": S2
+outputs:
+  S0: 0
+  S1: 1
+  S2: 2
+`
+
+func TestLoadYAML(t *testing.T) {
+
+	threeModFA, _ := NewFiniteAutomaton(
+		NewSet("S0", "S1", "S2"),
+		NewSet("0", "1"), "S0", NewSet("S0", "S1", "S2"),
+		map[string]map[string]string{
+			"S0": {"0": "S0", "1": "S1"},
+			"S1": {"0": "S2", "1": "S0"},
+			"S2": {"0": "S1", "1": "S2"},
+		})
+
+	fa, outputs, err := LoadYAML([]byte(threeModYAML))
+	if err != nil {
+		t.Fatalf("LoadYAML() error = %v", err)
+	}
+	if !fa.Equals(threeModFA) {
+		t.Errorf("LoadYAML() = %v, want %v", fa, threeModFA)
+	}
+	if want := (OutputMap[int]{"S0": 0, "S1": 1, "S2": 2}); !reflect.DeepEqual(outputs, want) {
+		t.Errorf("LoadYAML() outputs = %v, want %v", outputs, want)
+	}
+
+	fsm := fa.NewFiniteStateMachine()
+	fsm.OutputConverter = OutputConverterFromMap(outputs)
+	if got, err := fsm.GetFSMOutput("1010"); err != nil || got != 1 {
+		t.Errorf("GetFSMOutput() = %v, %v, want 1", got, err)
+	}
+
+	noOutputs := threeModYAML[:strings.Index(threeModYAML, "outputs:")]
+	if _, outputs, err := LoadYAML([]byte(noOutputs)); err != nil || outputs != nil {
+		t.Errorf("LoadYAML() without outputs = %v, %v, want nil outputs", outputs, err)
+	}
+}
+
+func TestLoadYAMLErrors(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "not yaml", data: "states: [S0", wantErr: "yaml"},
+		{name: "not a mapping", data: "- S0\n", wantErr: "line 1:"},
+		{name: "unknown key", data: "states: [S0]\nstart: S0\n", wantErr: "line 2: unknown key start"},
+		{name: "missing key", data: "states: [S0]\n", wantErr: "missing key alphabet"},
+		{name: "duplicate state", data: "states: [S0, S0]\nalphabet: [a]\ninitial: S0\naccepting: [S0]\ntransitions: {}\n", wantErr: "line 1: state S0 is listed twice"},
+		{name: "bad initial", data: "states: [S0]\nalphabet: [a]\ninitial: S1\naccepting: [S0]\ntransitions: {}\n", wantErr: "line 3: initial state S1"},
+		{name: "bad accepting", data: "states: [S0]\nalphabet: [a]\ninitial: S0\naccepting:\n  - S0\n  - S1\ntransitions: {}\n", wantErr: "line 6: accepting state S1"},
+		{name: "bad source", data: "states: [S0]\nalphabet: [a]\ninitial: S0\naccepting: [S0]\ntransitions:\n  S0: {a: S0}\n  S1: {a: S0}\n", wantErr: "line 7: state S1"},
+		{name: "bad input", data: "states: [S0]\nalphabet: [a]\ninitial: S0\naccepting: [S0]\ntransitions:\n  S0:\n    a: S0\n    b: S0\n", wantErr: "line 8: input b of state S0"},
+		{name: "bad target", data: "states: [S0]\nalphabet: [a]\ninitial: S0\naccepting: [S0]\ntransitions:\n  S0:\n    a: S9\n", wantErr: "line 7: target S9"},
+		{name: "missing input", data: "states: [S0]\nalphabet: [a, b]\ninitial: S0\naccepting: [S0]\ntransitions:\n  S0: {a: S0}\n", wantErr: "line 6: state S0 has no transition on input b"},
+		{name: "missing state", data: "states: [S0, S1]\nalphabet: [a]\ninitial: S0\naccepting: [S0]\ntransitions:\n  S0: {a: S0}\n", wantErr: "line 6: state S1 has no transitions"},
+		{name: "bad output", data: "states: [S0]\nalphabet: [a]\ninitial: S0\naccepting: [S0]\ntransitions:\n  S0: {a: S0}\noutputs:\n  S0: zero\n", wantErr: "line 8: output zero"},
+		{name: "missing output", data: "states: [S0, S1]\nalphabet: [a]\ninitial: S0\naccepting: [S0]\ntransitions:\n  S0: {a: S1}\n  S1: {a: S0}\noutputs:\n  S1: 1\n", wantErr: "line 9:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := LoadYAML([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadYAML() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFiniteAutomaton_ToYAML(t *testing.T) {
+
+	threeModFA, _, _ := LoadYAML([]byte(threeModYAML))
+	outputs := OutputMap[int]{"S0": 0, "S1": 1, "S2": 2}
+
+	data, err := threeModFA.ToYAML(outputs)
+	if err != nil {
+		t.Fatalf("ToYAML() error = %v", err)
+	}
+	want := `states: [S0, S1, S2]
+alphabet: ["0", "1"]
+initial: S0
+accepting: [S0, S1, S2]
+transitions:
+    S0: {"0": S0, "1": S1}
+    S1: {"0": S2, "1": S0}
+    S2: {"0": S1, "1": S2}
+outputs:
+    S0: 0
+    S1: 1
+    S2: 2
+`
+	if string(data) != want {
+		t.Errorf("ToYAML() = \n%s\n want \n%s", data, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "threeMod.yaml")
+	if err := threeModFA.SaveYAMLFile(path, outputs); err != nil {
+		t.Fatalf("SaveYAMLFile() error = %v", err)
+	}
+	fa, gotOutputs, err := LoadYAMLFile(path)
+	if err != nil {
+		t.Fatalf("LoadYAMLFile() error = %v", err)
+	}
+	if !fa.Equals(threeModFA) || !reflect.DeepEqual(gotOutputs, outputs) {
+		t.Errorf("LoadYAMLFile() = %v, %v, want %v, %v", fa, gotOutputs, threeModFA, outputs)
+	}
+
+	if _, _, err := LoadYAMLFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Errorf("LoadYAMLFile() expected error on a missing file")
+	}
+}
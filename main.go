@@ -1,15 +1,16 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
 	"github.com/nabbas-ca/finite-automaton/fsm"
 )
 
-// modThree is the function that implements the FSM/FA api. Need to initialize FA and then create an FSM from it, which will process the input via GetFSMOutput func
-func modThree(input string) int {
-	threeModFA, err := fsm.NewFiniteAutomaton(
+// newThreeModFA returns the FA computing the remainder of a binary number divided by 3
+func newThreeModFA() (*fsm.FiniteAutomaton, error) {
+	return fsm.NewFiniteAutomaton(
 		fsm.NewSet("S0", "S1", "S2"),
 		fsm.NewSet("0", "1"), "S0", fsm.NewSet("S0", "S1", "S2"),
 		map[string]map[string]string{
@@ -17,6 +18,11 @@ func modThree(input string) int {
 			"S1": {"0": "S2", "1": "S0"},
 			"S2": {"0": "S1", "1": "S2"},
 		})
+}
+
+// modThree is the function that implements the FSM/FA api. Need to initialize FA and then create an FSM from it, which will process the input via GetFSMOutput func
+func modThree(input string) int {
+	threeModFA, err := newThreeModFA()
 
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating three mod FSM. Error:= %v\n", err)
@@ -32,12 +38,65 @@ func modThree(input string) int {
 	return output
 
 }
+
+// writeSVG implements the svg subcommand: it renders an FA, the mod three FA or one loaded from a YAML file, as SVG on stdout.
+// With -trace, the run of the FA on that input is highlighted
+func writeSVG(args []string) error {
+	flags := flag.NewFlagSet("svg", flag.ContinueOnError)
+	layout := flags.String("layout", "layered", "state placement, layered or circular")
+	file := flags.String("fa", "", "YAML definition of the FA to render, the mod three FA if empty")
+	trace := flags.String("trace", "", "input whose run is highlighted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	options := fsm.SVGOptions{}
+	switch *layout {
+	case "layered":
+		options.Layout = fsm.LayeredLayout
+	case "circular":
+		options.Layout = fsm.CircularLayout
+	default:
+		return fmt.Errorf("unknown layout %s, expected layered or circular", *layout)
+	}
+
+	fa, err := newThreeModFA()
+	if *file != "" {
+		fa, _, err = fsm.LoadYAMLFile(*file)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *trace != "" {
+		machine := fa.NewFiniteStateMachine()
+		machine.Tracer = fsm.NewTrace()
+		for _, r := range *trace {
+			if err := machine.ProcessInputRune(string(r)); err != nil {
+				return err
+			}
+		}
+		options.Trace = machine.Tracer
+	}
+	return fa.WriteSVG(os.Stdout, options)
+}
+
 func main() {
 	// TODO: use Cobra (possibly viper) to parse command line and create config file for the following
 
 	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: fsm <input>\n")
+		fmt.Fprintf(os.Stderr, "Usage: fsm <input>\n       fsm svg [-layout layered|circular] [-fa fa.yaml] [-trace <input>] > fa.svg\n")
+		os.Exit(2)
 	}
+
+	if os.Args[1] == "svg" {
+		if err := writeSVG(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing SVG. Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	input := os.Args[1] // input string is first arg
 
 	output := modThree(input)